@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
+	"strings"
 
 	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/hashes"
 	op "github.com/fgrehm/brinfo/core/operations"
+	"github.com/fgrehm/brinfo/core/schema"
 	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
+	"github.com/fgrehm/brinfo/core/validation"
 
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
@@ -17,13 +21,14 @@ import (
 var scrapeArticleCmd = &cobra.Command{
 	Use:   "article [URL]",
 	Short: "Scrape contents of articles",
-	Args:  cobra.ExactArgs(1),
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if _, err := url.ParseRequestURI(args[0]); err != nil {
+		url, html, err := readInput(args)
+		if err != nil {
 			return err
 		}
 
-		return runArticleScraper(cmd.Context(), args[0])
+		return runArticleScraper(cmd.Context(), url, html)
 	},
 }
 
@@ -31,22 +36,24 @@ func init() {
 	scrapeArticleCmd.Flags().StringVarP(&mergeWithFlag, "merge-with", "m", "", "JSON to merge with the scraped article data")
 	scrapeArticleCmd.Flags().StringVarP(&extraDataFlag, "extra-data", "e", "", "Extra JSON to merge with the scraped article data")
 	scrapeArticleCmd.Flags().StringVarP(&sourceGUIDFlag, "source-guid", "s", "", "A string that represents the JSON to merge with the scraped article data")
+	scrapeArticleCmd.Flags().StringVarP(&contentEngineFlag, "content-engine", "", xt.ContentEngineHTMLInfo, contentEngineUsage)
 	scrapeArticleCmd.Flags().StringVarP(&customExtractorsFlag, "custom-extractors", "", "", "A string that represents the JSON of custom extractors to use")
+	scrapeArticleCmd.Flags().StringArrayVarP(&urlDatePatternsFlag, "url-date-pattern", "", nil, "Regular expression with year, month and day named groups for inferring the publication date from the URL, can be repeated")
+	scrapeArticleCmd.Flags().StringVarP(&urlRulesFlag, "url-rules", "", "", urlRulesUsage)
+	scrapeArticleCmd.Flags().StringVarP(&hashAlgorithmFlag, "hash-algorithm", "", hashes.AlgorithmSHA1, hashAlgorithmUsage)
+	scrapeArticleCmd.Flags().BoolVarP(&useFinalURLFlag, "use-final-url", "", false, "Use the URL the article is served from after redirects instead of the one provided")
+	scrapeArticleCmd.Flags().StringVarP(&validationConfigFlag, "validation-config", "", "", "JSON with the validation settings of the source, with severities, require_image, min_full_text_length (in words), site_name and languages")
+	scrapeArticleCmd.Flags().StringVarP(&schemaVersionFlag, "schema-version", "", schema.DefaultVersion, schemaVersionUsage)
+	scrapeArticleCmd.Flags().BoolVarP(&downloadAttachmentsFlag, "download-attachments", "", false, "Download the documents linked from the article and archive them along with its data")
+	scrapeArticleCmd.Flags().StringVarP(&fromFileFlag, "from-file", "f", "", "Read the page from a local file instead of fetching it, use - for stdin")
+	scrapeArticleCmd.Flags().StringVarP(&baseURLFlag, "base-url", "", "", "URL of the page read with --from-file, used for resolving relative links")
 
 	if err := scrapeArticleCmd.MarkFlagRequired("source-guid"); err != nil {
 		panic(err)
 	}
 }
 
-type ArticleData struct {
-	*core.ArticleData
-
-	Key    string                 `json:"key"`
-	Source string                 `json:"source_guid"`
-	Extra  map[string]interface{} `json:"extra,omitempty"`
-}
-
-func runArticleScraper(ctx context.Context, url string) error {
+func runArticleScraper(ctx context.Context, url string, html []byte) error {
 	var (
 		dataToMerge *core.ArticleData
 		extraData   map[string]interface{}
@@ -68,40 +75,102 @@ func runArticleScraper(ctx context.Context, url string) error {
 		}
 	}
 
-	extractors := []xt.Extractor{xt.BasicArticle()}
-	if customExtractorsFlag != "" {
-		customExtractors, err := xt.FromJSON([]byte(customExtractorsFlag))
-		if err != nil {
+	extractors, err := articleExtractors(contentEngineFlag, customExtractorsFlag)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	location, err := timezoneLocation()
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	urlRules, err := parseURLRules(urlRulesFlag)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	validationConfig := &validation.Config{}
+	if validationConfigFlag != "" {
+		if validationConfig, err = validation.ParseConfig([]byte(validationConfigFlag), validation.DefaultRules); err != nil {
 			logger.Fatal(err.Error())
 		}
-		extractors = append(extractors, customExtractors...)
 	}
 
 	logger.Infof("Scraping %s", url)
 	data, err := op.ScrapeArticle(ctx, op.ScrapeArticleArgs{
-		UseCache:   cfgCache,
-		URL:        url,
-		Extractors: extractors,
-		MergeWith:  dataToMerge,
+		UseCache:            cfgCache,
+		URL:                 url,
+		HTML:                html,
+		Extractors:          extractors,
+		MergeWith:           dataToMerge,
+		Location:            location,
+		URLDatePatterns:     urlDatePatternsFlag,
+		URLRules:            urlRules,
+		UseFinalURL:         useFinalURLFlag,
+		HashAlgorithm:       hashAlgorithmFlag,
+		DownloadAttachments: downloadAttachmentsFlag,
 	})
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
 
-	payload := &ArticleData{
+	results := validation.Validate(data, validationConfig)
+	payload := &schema.Payload{
 		ArticleData: data,
 		Extra:       extraData,
 		Key:         fmt.Sprintf("%s/article-%s-%s.json", sourceGUIDFlag, data.URLHash, data.FullTextHash),
 		Source:      sourceGUIDFlag,
+		Validation:  results,
 	}
-	jsonData, err := json.MarshalIndent(payload, "", "  ")
+	jsonData, err := schema.Marshal(payload, schemaVersionFlag)
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
 	fmt.Println(string(jsonData))
 
-	if valid, msgs := data.ValidForIngestion(); !valid {
-		logger.Fatalf("Data is invalid for ingestion: %v", msgs)
+	for _, r := range results {
+		logger.Warn(r.String())
+	}
+	if errors := validation.Errors(results); len(errors) > 0 {
+		messages := []string{}
+		for _, e := range errors {
+			messages = append(messages, fmt.Sprintf("%s (%s)", e.Message, e.Rule))
+		}
+		return fmt.Errorf("data is invalid for ingestion: %s", strings.Join(messages, ", "))
 	}
 	return nil
 }
+
+const (
+	contentEngineUsage = "Engine used for extracting the full text of articles, either htmlinfo or main-content (required for the structured full text)"
+	urlRulesUsage      = "JSON array of rules for normalizing the URLs of the source, with host, keep_params, drop_params and trim_suffixes"
+	hashAlgorithmUsage = "Algorithm used for the URL and full text hashes, either sha1 or sha256"
+)
+
+func parseURLRules(urlRulesJSON string) ([]urls.Rule, error) {
+	var urlRules []urls.Rule
+	if urlRulesJSON == "" {
+		return urlRules, nil
+	}
+	if err := json.Unmarshal([]byte(urlRulesJSON), &urlRules); err != nil {
+		return nil, err
+	}
+	return urlRules, nil
+}
+
+func articleExtractors(contentEngine, customExtractorsJSON string) ([]xt.Extractor, error) {
+	basicArticle, err := xt.BasicArticleWithContentEngine(contentEngine)
+	if err != nil {
+		return nil, err
+	}
+	extractors := []xt.Extractor{basicArticle}
+	if customExtractorsJSON != "" {
+		customExtractors, err := xt.FromJSON([]byte(customExtractorsJSON))
+		if err != nil {
+			return nil, err
+		}
+		extractors = append(extractors, customExtractors...)
+	}
+	return extractors, nil
+}
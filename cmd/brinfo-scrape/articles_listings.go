@@ -3,7 +3,6 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"net/url"
 
 	op "github.com/fgrehm/brinfo/core/operations"
 
@@ -20,19 +19,25 @@ var scrapeArticlesListingFlags = struct {
 var scrapeArticlesListingCmd = &cobra.Command{
 	Use:   "articles-listing [URL]",
 	Short: "Extract a list of article links and metadata from a page that has a list of articles",
-	Args:  cobra.ExactArgs(1),
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		_, err := url.ParseRequestURI(args[0])
+		url, html, err := readInput(args)
+		if err != nil {
+			return err
+		}
+		location, err := timezoneLocation()
 		if err != nil {
 			return err
 		}
 
 		data, err := op.ScrapeArticlesListing(cmd.Context(), op.ScrapeArticlesListingArgs{
-			URL:                  args[0],
+			URL:                  url,
+			HTML:                 html,
 			LinkContainer:        scrapeArticlesListingFlags.linkContainer,
 			URLExtractor:         scrapeArticlesListingFlags.urlExtractor,
 			PublishedAtExtractor: scrapeArticlesListingFlags.publishedAtExtractor,
 			ImageURLExtractor:    scrapeArticlesListingFlags.imageURLExtractor,
+			Location:             location,
 			UseCache:             cfgCache,
 		})
 		if err != nil {
@@ -55,6 +60,9 @@ func init() {
 	scrapeArticlesListingCmd.Flags().StringVarP(&scrapeArticlesListingFlags.publishedAtExtractor, "published-at-extractor", "p", "", "CSS selector for the actual link, nested under the elements wrapped by the container")
 	scrapeArticlesListingCmd.Flags().StringVarP(&scrapeArticlesListingFlags.imageURLExtractor, "image-url-extractor", "i", "", "CSS selector for the actual link, nested under the elements wrapped by the container")
 
+	scrapeArticlesListingCmd.Flags().StringVarP(&fromFileFlag, "from-file", "f", "", "Read the page from a local file instead of fetching it, use - for stdin")
+	scrapeArticlesListingCmd.Flags().StringVarP(&baseURLFlag, "base-url", "", "", "URL of the page read with --from-file, used for resolving relative links")
+
 	if err := scrapeArticlesListingCmd.MarkFlagRequired("link-container"); err != nil {
 		panic(err)
 	}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/duplicates"
+	op "github.com/fgrehm/brinfo/core/operations"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+var dedupeFlags = struct {
+	maxDistance int
+}{}
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe PATH...",
+	Short: "Find articles republished by multiple sources",
+	Long: `Find articles republished by multiple sources
+
+Each PATH is a JSON payload emitted by the article command or a directory of
+them. Articles whose full text fingerprints differ on at most --max-distance
+bits are grouped together and the one published first is reported as the
+original.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clusters, err := op.DedupeArticles(op.DedupeArticlesArgs{
+			Paths:       args,
+			MaxDistance: dedupeFlags.maxDistance,
+		})
+		if err != nil {
+			return err
+		}
+
+		log.FromContext(cmd.Context()).Infof("Found %d groups of duplicates", len(clusters))
+		for _, c := range clusters {
+			out := &dedupedCluster{Original: summarize(c.Original, 0), Duplicates: []*dedupedArticle{}}
+			for _, d := range c.Duplicates {
+				out.Duplicates = append(out.Duplicates, summarize(d.Article, d.Distance))
+			}
+			jsonData, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(jsonData))
+		}
+		return nil
+	},
+}
+
+type dedupedCluster struct {
+	Original   *dedupedArticle   `json:"original"`
+	Duplicates []*dedupedArticle `json:"duplicates"`
+}
+
+type dedupedArticle struct {
+	URL         string     `json:"url"`
+	URLHash     string     `json:"url_hash"`
+	Title       string     `json:"title"`
+	PublishedAt *time.Time `json:"published_at"`
+	Distance    int        `json:"distance,omitempty"`
+}
+
+func summarize(data *core.ArticleData, distance int) *dedupedArticle {
+	return &dedupedArticle{
+		URL:         data.URL,
+		URLHash:     data.URLHash,
+		Title:       data.Title,
+		PublishedAt: data.PublishedAt,
+		Distance:    distance,
+	}
+}
+
+func init() {
+	dedupeCmd.Flags().IntVarP(&dedupeFlags.maxDistance, "max-distance", "", duplicates.DefaultMaxDistance, "Number of bits the fingerprints of duplicates may differ on")
+}
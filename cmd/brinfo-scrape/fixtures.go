@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fgrehm/brinfo/core/fixtures"
+	"github.com/fgrehm/brinfo/core/hashes"
+	op "github.com/fgrehm/brinfo/core/operations"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+var fixtureFlags = struct {
+	dir              string
+	name             string
+	customExtractors string
+}{}
+
+var fixtureCmd = &cobra.Command{
+	Use:   "fixture",
+	Short: "Manage pages kept for detecting regressions on extractors",
+}
+
+var fixtureRecordCmd = &cobra.Command{
+	Use:   "record [URL]",
+	Short: "Store a page along with the data currently extracted from it",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, html, err := readInput(args)
+		if err != nil {
+			return err
+		}
+		urlRules, err := parseURLRules(urlRulesFlag)
+		if err != nil {
+			return err
+		}
+
+		f, err := op.RecordFixture(cmd.Context(), op.RecordFixtureArgs{
+			UseCache:         cfgCache,
+			URL:              url,
+			HTML:             html,
+			CustomExtractors: fixtureFlags.customExtractors,
+			Timezone:         timezoneFlag,
+			ContentEngine:    contentEngineFlag,
+			URLRules:         urlRules,
+			HashAlgorithm:    hashAlgorithmFlag,
+			Dir:              fixtureFlags.dir,
+			Name:             fixtureFlags.name,
+		})
+		if err != nil {
+			return err
+		}
+
+		log.FromContext(cmd.Context()).Infof("Recorded fixture %s", f.Name)
+		return nil
+	},
+}
+
+var fixtureVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-run extractors over all fixtures and report differences",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := fixtures.VerifyAll(cmd.Context(), fixtureFlags.dir)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Passed() {
+				fmt.Printf("ok    %s\n", r.Fixture.Name)
+				continue
+			}
+
+			failed++
+			fmt.Printf("FAIL  %s (%s)\n", r.Fixture.Name, r.Fixture.URL)
+			for _, c := range r.Changes {
+				before, _ := json.Marshal(c.Before)
+				after, _ := json.Marshal(c.After)
+				fmt.Printf("      %s:\n        - %s\n        + %s\n", c.Field, before, after)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d fixtures failed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	fixtureCmd.PersistentFlags().StringVarP(&fixtureFlags.dir, "dir", "d", "fixtures", "Directory where fixtures are kept")
+
+	fixtureRecordCmd.Flags().StringVarP(&fixtureFlags.name, "name", "n", "", "Name of the fixture, generated from the URL by default")
+	fixtureRecordCmd.Flags().StringVarP(&contentEngineFlag, "content-engine", "", xt.ContentEngineHTMLInfo, contentEngineUsage)
+	fixtureRecordCmd.Flags().StringVarP(&fixtureFlags.customExtractors, "custom-extractors", "", "", "A string that represents the JSON of custom extractors to use")
+	fixtureRecordCmd.Flags().StringVarP(&urlRulesFlag, "url-rules", "", "", urlRulesUsage)
+	fixtureRecordCmd.Flags().StringVarP(&hashAlgorithmFlag, "hash-algorithm", "", hashes.AlgorithmSHA1, hashAlgorithmUsage)
+	fixtureRecordCmd.Flags().StringVarP(&fromFileFlag, "from-file", "f", "", "Read the page from a local file instead of fetching it, use - for stdin")
+	fixtureRecordCmd.Flags().StringVarP(&baseURLFlag, "base-url", "", "", "URL of the page read with --from-file, used for resolving relative links")
+
+	fixtureCmd.AddCommand(fixtureRecordCmd)
+	fixtureCmd.AddCommand(fixtureVerifyCmd)
+}
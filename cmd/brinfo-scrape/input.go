@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+)
+
+// readInput returns the URL of the page to scrape along with its contents when
+// it is read from a local file or stdin (--from-file -). A nil body means that
+// the page should be fetched over HTTP.
+func readInput(args []string) (string, []byte, error) {
+	if fromFileFlag == "" {
+		if len(args) != 1 {
+			return "", nil, errors.New("a URL must be provided when --from-file is not set")
+		}
+		if _, err := url.ParseRequestURI(args[0]); err != nil {
+			return "", nil, err
+		}
+		return args[0], nil, nil
+	}
+
+	if len(args) > 0 {
+		return "", nil, errors.New("use --base-url to set the URL of pages read with --from-file")
+	}
+
+	pageURL := baseURLFlag
+	if pageURL != "" {
+		if _, err := url.ParseRequestURI(pageURL); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if fromFileFlag == "-" {
+		if pageURL == "" {
+			return "", nil, errors.New("--base-url is required when reading from stdin")
+		}
+		body, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return "", nil, err
+		}
+		return pageURL, body, nil
+	}
+
+	body, err := ioutil.ReadFile(fromFileFlag)
+	if err != nil {
+		return "", nil, err
+	}
+	if pageURL == "" {
+		absPath, err := filepath.Abs(fromFileFlag)
+		if err != nil {
+			return "", nil, err
+		}
+		pageURL = "file://" + filepath.ToSlash(absPath)
+	}
+	return pageURL, body, nil
+}
+
+// timezoneLocation returns the location set with --timezone, nil means that it
+// should be derived from the URL of the page.
+func timezoneLocation() (*time.Location, error) {
+	if timezoneFlag == "" {
+		return nil, nil
+	}
+	return dates.LoadLocation(timezoneFlag)
+}
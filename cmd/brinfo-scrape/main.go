@@ -10,11 +10,22 @@ import (
 )
 
 var (
-	cfgCache             bool
-	mergeWithFlag        string
-	sourceGUIDFlag       string
-	customExtractorsFlag string
-	extraDataFlag        string
+	cfgCache                bool
+	mergeWithFlag           string
+	sourceGUIDFlag          string
+	customExtractorsFlag    string
+	extraDataFlag           string
+	fromFileFlag            string
+	baseURLFlag             string
+	timezoneFlag            string
+	urlDatePatternsFlag     []string
+	urlRulesFlag            string
+	useFinalURLFlag         bool
+	hashAlgorithmFlag       string
+	schemaVersionFlag       string
+	validationConfigFlag    string
+	contentEngineFlag       string
+	downloadAttachmentsFlag bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -25,9 +36,17 @@ var rootCmd = &cobra.Command{
 
 func main() {
 	rootCmd.PersistentFlags().BoolVarP(&cfgCache, "use-cache", "", false, "enable caching, data is kept on .brinfo-cache/")
+	rootCmd.PersistentFlags().StringVarP(&timezoneFlag, "timezone", "", "", "state (like AM) or IANA timezone used for times without offset, derived from the URL by default")
 
 	rootCmd.AddCommand(scrapeArticleCmd)
 	rootCmd.AddCommand(scrapeArticlesListingCmd)
+	rootCmd.AddCommand(reextractCmd)
+	rootCmd.AddCommand(tryCmd)
+	rootCmd.AddCommand(suggestListingCmd)
+	rootCmd.AddCommand(fixtureCmd)
+	rootCmd.AddCommand(dedupeCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(validateCmd)
 
 	log.SetHandler(cli.Default)
 	log.SetLevel(log.DebugLevel)
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/hashes"
+	op "github.com/fgrehm/brinfo/core/operations"
+	"github.com/fgrehm/brinfo/core/scrapers"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+var reextractFlags = struct {
+	cacheURL         string
+	baseURL          string
+	customExtractors string
+}{}
+
+var reextractCmd = &cobra.Command{
+	Use:   "reextract [PATH]",
+	Short: "Re-run article extractors over pages that have been fetched before",
+	Long: `Re-run article extractors over pages that have been fetched before
+
+PATH can be a JSON payload emitted by the article command (or a directory of
+them), a HTML file, a directory of HTML files or an entry of the cache kept on
+.brinfo-cache/. Use --cache-url to look up a page on the cache by its URL.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			pages []*op.StoredPage
+			err   error
+		)
+
+		if reextractFlags.cacheURL != "" {
+			if len(args) > 0 {
+				return errors.New("PATH and --cache-url can't be used together")
+			}
+			page, err := op.LoadCachedPage(reextractFlags.cacheURL)
+			if err != nil {
+				return err
+			}
+			pages = []*op.StoredPage{page}
+		} else {
+			if len(args) == 0 {
+				return errors.New("either PATH or --cache-url must be provided")
+			}
+			pages, err = op.LoadStoredPages(args[0], reextractFlags.baseURL)
+			if err != nil {
+				return err
+			}
+		}
+
+		extractors, err := articleExtractors(contentEngineFlag, reextractFlags.customExtractors)
+		if err != nil {
+			return err
+		}
+		location, err := timezoneLocation()
+		if err != nil {
+			return err
+		}
+		urlRules, err := parseURLRules(urlRulesFlag)
+		if err != nil {
+			return err
+		}
+
+		logger := log.FromContext(cmd.Context())
+		classifier := scrapers.NewPageClassifier()
+		for _, page := range pages {
+			logger.Infof("Re-extracting %s", page.Path)
+			result, err := op.ReextractArticle(cmd.Context(), op.ReextractArticleArgs{
+				Page:          page,
+				Extractors:    extractors,
+				Location:      location,
+				URLRules:      urlRules,
+				HashAlgorithm: hashAlgorithmFlag,
+				Classifier:    classifier,
+			})
+			if err != nil {
+				return fmt.Errorf("%s: %s", page.Path, err)
+			}
+
+			if page.Previous != nil {
+				logChanges(logger, result.Changes)
+			}
+
+			out, err := json.MarshalIndent(&reextractedArticle{result.Data, result.Changes}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		}
+
+		return nil
+	},
+}
+
+type reextractedArticle struct {
+	*core.ArticleData
+
+	Changes []*core.FieldChange `json:"changes,omitempty"`
+}
+
+func init() {
+	reextractCmd.Flags().StringVarP(&reextractFlags.cacheURL, "cache-url", "", "", "URL of a page kept on the cache to re-extract")
+	reextractCmd.Flags().StringVarP(&reextractFlags.baseURL, "base-url", "", "", "URL to use for pages that don't have one recorded")
+	reextractCmd.Flags().StringVarP(&contentEngineFlag, "content-engine", "", xt.ContentEngineHTMLInfo, contentEngineUsage)
+	reextractCmd.Flags().StringVarP(&reextractFlags.customExtractors, "custom-extractors", "", "", "A string that represents the JSON of custom extractors to use")
+	reextractCmd.Flags().StringVarP(&urlRulesFlag, "url-rules", "", "", urlRulesUsage)
+	reextractCmd.Flags().StringVarP(&hashAlgorithmFlag, "hash-algorithm", "", hashes.AlgorithmSHA1, hashAlgorithmUsage)
+}
+
+func logChanges(logger log.Interface, changes []*core.FieldChange) {
+	if len(changes) == 0 {
+		logger.Info("No changes compared to previous extraction")
+		return
+	}
+
+	fields := []string{}
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	logger.Infof("Changed fields: %s", strings.Join(fields, ", "))
+}
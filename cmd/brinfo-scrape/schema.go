@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	op "github.com/fgrehm/brinfo/core/operations"
+	"github.com/fgrehm/brinfo/core/schema"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+var schemaVersionUsage = "Version of the output format, one of " + strings.Join(schema.Versions, ", ")
+
+var schemaFlags = struct {
+	version string
+}{}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the articles emitted",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := schema.Schema(schemaFlags.version)
+		if err != nil {
+			return err
+		}
+		jsonData, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate PATH...",
+	Short: "Check stored articles against the schema of their version",
+	Long: `Check stored articles against the schema of their version
+
+Each PATH is a JSON payload emitted by the article command or a directory of
+them. Payloads without a schema_version are checked against version 1.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := op.ValidatePayloads(args)
+		if err != nil {
+			return err
+		}
+
+		logger := log.FromContext(cmd.Context())
+		invalid := 0
+		for _, r := range results {
+			if len(r.Problems) == 0 {
+				continue
+			}
+			invalid++
+			logger.Errorf("%s is invalid:\n  %s", r.Path, strings.Join(r.Problems, "\n  "))
+		}
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d payloads are invalid", invalid, len(results))
+		}
+		logger.Infof("All %d payloads are valid", len(results))
+		return nil
+	},
+}
+
+func init() {
+	schemaCmd.Flags().StringVarP(&schemaFlags.version, "schema-version", "", schema.LatestVersion, schemaVersionUsage)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	op "github.com/fgrehm/brinfo/core/operations"
+
+	"github.com/spf13/cobra"
+)
+
+var suggestListingFlags = struct {
+	limit int
+}{}
+
+var suggestListingCmd = &cobra.Command{
+	Use:   "suggest-listing [URL]",
+	Short: "Suggest articles-listing flags for a page that has a list of articles",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		url, html, err := readInput(args)
+		if err != nil {
+			return err
+		}
+
+		suggestions, err := op.SuggestListing(cmd.Context(), op.SuggestListingArgs{
+			URL:      url,
+			HTML:     html,
+			UseCache: cfgCache,
+		})
+		if err != nil {
+			return err
+		}
+		if len(suggestions) == 0 {
+			return errors.New("no repeated structures with links were found")
+		}
+
+		if len(suggestions) > suggestListingFlags.limit {
+			suggestions = suggestions[:suggestListingFlags.limit]
+		}
+		for i, s := range suggestions {
+			flags := []string{
+				fmt.Sprintf("--link-container %s", shellQuote(s.Config.LinkContainer)),
+				fmt.Sprintf("--url-extractor %s", shellQuote(s.Config.URLExtractor)),
+			}
+			if s.Config.PublishedAtExtractor != "" {
+				flags = append(flags, fmt.Sprintf("--published-at-extractor %s", shellQuote(s.Config.PublishedAtExtractor)))
+			}
+			if s.Config.ImageURLExtractor != "" {
+				flags = append(flags, fmt.Sprintf("--image-url-extractor %s", shellQuote(s.Config.ImageURLExtractor)))
+			}
+
+			fmt.Printf("#%d (score %.2f, %d items)\n", i+1, s.Score, s.Items)
+			fmt.Printf("  %s\n", strings.Join(flags, " "))
+			for _, link := range s.Preview {
+				fmt.Printf("    %s", link.URL)
+				if link.PublishedAt != nil {
+					fmt.Printf(" (%s)", link.PublishedAt.Format("2006-01-02 15:04"))
+				}
+				fmt.Println()
+			}
+			fmt.Println()
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	suggestListingCmd.Flags().IntVarP(&suggestListingFlags.limit, "limit", "n", 3, "Number of suggestions to print")
+	suggestListingCmd.Flags().StringVarP(&fromFileFlag, "from-file", "f", "", "Read the page from a local file instead of fetching it, use - for stdin")
+	suggestListingCmd.Flags().StringVarP(&baseURLFlag, "base-url", "", "", "URL of the page read with --from-file, used for resolving relative links")
+}
+
+func shellQuote(str string) string {
+	return "'" + strings.Replace(str, "'", `'"'"'`, -1) + "'"
+}
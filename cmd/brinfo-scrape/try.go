@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core/dates"
+	op "github.com/fgrehm/brinfo/core/operations"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cobra"
+)
+
+var tryFlags = struct {
+	extractor   string
+	interactive bool
+}{}
+
+var tryCmd = &cobra.Command{
+	Use:   "try [URL]",
+	Short: "Evaluate extractors against a page, printing the nodes matched and values extracted",
+	Long: `Evaluate extractors against a page, printing the nodes matched and values extracted
+
+The page is fetched (or read with --from-file) only once. Extractors can be
+provided in the "selector | attribute" format or as the JSON used by
+--custom-extractors. With --interactive, extractors are read from stdin, one
+per line, and evaluated against the same document.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tryFlags.extractor == "" && !tryFlags.interactive {
+			return errors.New("either --extractor or --interactive must be provided")
+		}
+		if tryFlags.interactive && fromFileFlag == "-" {
+			return errors.New("--interactive can't be used when reading the page from stdin")
+		}
+
+		url, html, err := readInput(args)
+		if err != nil {
+			return err
+		}
+		if html == nil {
+			page, err := op.FetchPage(cfgCache, url)
+			if err != nil {
+				return err
+			}
+			html = page.HTML
+		}
+
+		doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(html))
+		if err != nil {
+			return err
+		}
+		location, err := timezoneLocation()
+		if err != nil {
+			return err
+		}
+		if location == nil {
+			location = dates.LocationForURL(url)
+		}
+		extractorArgs := xt.ExtractorArgs{
+			Context:  cmd.Context(),
+			URL:      url,
+			Root:     doc.Selection,
+			Location: location,
+		}
+
+		if tryFlags.extractor != "" {
+			evaluateExtractor(os.Stdout, extractorArgs, tryFlags.extractor)
+		}
+		if tryFlags.interactive {
+			return tryInteractively(os.Stdin, os.Stdout, extractorArgs)
+		}
+		return nil
+	},
+}
+
+func init() {
+	tryCmd.Flags().StringVarP(&tryFlags.extractor, "extractor", "x", "", "Extractor to evaluate")
+	tryCmd.Flags().BoolVarP(&tryFlags.interactive, "interactive", "i", false, "Read extractors to evaluate from stdin")
+	tryCmd.Flags().StringVarP(&fromFileFlag, "from-file", "f", "", "Read the page from a local file instead of fetching it, use - for stdin")
+	tryCmd.Flags().StringVarP(&baseURLFlag, "base-url", "", "", "URL of the page read with --from-file, used for resolving relative links")
+}
+
+func tryInteractively(in io.Reader, out io.Writer, args xt.ExtractorArgs) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+		evaluateExtractor(out, args, line)
+	}
+}
+
+func evaluateExtractor(out io.Writer, args xt.ExtractorArgs, extractorStr string) {
+	if strings.HasPrefix(extractorStr, "{") {
+		evaluateJSONExtractors(out, args, extractorStr)
+		return
+	}
+
+	spec, err := xt.ParseSpec(extractorStr)
+	if err != nil {
+		fmt.Fprintf(out, "error: %s\n", err)
+		return
+	}
+
+	matches, err := spec.Matches(args)
+	if err != nil {
+		fmt.Fprintf(out, "error: %s\n", err)
+		return
+	}
+
+	fmt.Fprintf(out, "%d node(s) matched by '%s'\n", len(matches), spec.Selector)
+	for i, m := range matches {
+		fmt.Fprintf(out, "\n[%d] %s\n", i, m.OuterHTML)
+		if !m.Found {
+			fmt.Fprintf(out, "    raw:    attribute '%s' not found\n", spec.Attribute)
+			continue
+		}
+		fmt.Fprintf(out, "    raw:    %q\n", m.Raw)
+		if m.Err != nil {
+			fmt.Fprintf(out, "    parsed: error: %s\n", m.Err)
+		} else {
+			fmt.Fprintf(out, "    parsed: %s\n", formatValue(m.Value))
+		}
+	}
+
+	result, err := spec.Extractor().Extract(args)
+	if err != nil {
+		fmt.Fprintf(out, "\nresult: error: %s\n", err)
+	} else {
+		fmt.Fprintf(out, "\nresult: %s\n", formatValue(result))
+	}
+}
+
+func evaluateJSONExtractors(out io.Writer, args xt.ExtractorArgs, extractorsJSON string) {
+	extractors, err := xt.FromJSON([]byte(extractorsJSON))
+	if err != nil {
+		fmt.Fprintf(out, "error: %s\n", err)
+		return
+	}
+
+	for _, e := range extractors {
+		result, err := e.Extract(args)
+		if err != nil {
+			fmt.Fprintf(out, "error: %s\n", err)
+			continue
+		}
+		fmt.Fprintln(out, formatValue(result))
+	}
+}
+
+func formatValue(value interface{}) string {
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(out)
+}
@@ -0,0 +1,253 @@
+// Package content finds the main content of article pages, leaving out the
+// menus, sharing widgets, cookie banners and related news boxes that surround
+// it.
+package content
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// minContentLength is the minimum length of the text of a known content
+// container for it to be used instead of scoring the nodes of the page.
+const minContentLength = 140
+
+// ContentSelectors are containers known to hold the main content of pages
+// built with the platforms commonly used by government websites (Plone,
+// WordPress, Drupal and Joomla), tried in order before scoring the page.
+var ContentSelectors = []string{
+	`#parent-fieldname-text`,
+	`[property="rnews:articleBody"]`,
+	`[itemprop="articleBody"]`,
+	`.entry-content`,
+	`.post-content`,
+	`.field-name-body`,
+	`.item-page .article-body`,
+	`#content-core`,
+}
+
+// BoilerplateSelectors are removed from the page before looking for the main
+// content.
+var BoilerplateSelectors = []string{
+	`script`, `style`, `noscript`, `template`, `form`, `button`,
+	`nav`, `header`, `footer`, `aside`, `[role="navigation"]`, `[role="banner"]`,
+	`[role="contentinfo"]`, `[aria-hidden="true"]`,
+	// Plone
+	`#portal-header`, `#portal-footer`, `#portal-footer-wrapper`, `#portal-column-one`,
+	`#portal-column-two`, `#portal-breadcrumbs`, `#viewlet-above-content`,
+	`#viewlet-below-content`, `#viewlet-social-like`, `.documentActions`,
+	`.documentByLine`, `.portlet`, `.link-externo`,
+	// WordPress
+	`.sharedaddy`, `.jp-relatedposts`, `#comments`, `.comments-area`, `.post-navigation`,
+	`.wp-block-buttons`,
+	// Common widgets
+	`.breadcrumb`, `.breadcrumbs`, `.social-links`, `.share`, `.sharing`, `.compartilhe`,
+	`.addthis_toolbox`, `.cookie-banner`, `#cookie-banner`, `.cookies`, `#lgpd`, `.lgpd`,
+	`.related`, `.relacionadas`, `.noticias-relacionadas`, `.tags`, `.banner`, `.menu`,
+}
+
+var (
+	positiveRegexp  = regexp.MustCompile(`(?i)article|body|content|entry|main|materia|noticia|post|story|text|texto`)
+	negativeRegexp  = regexp.MustCompile(`(?i)(?:^|[\s_-])(?:banners?|breadcrumbs?|comments?|compartilh\w*|cookies?|footer|footnotes?|lgpd|menu|meta|nav|navbar|navigation|related|relacionad[oa]s?|share|sharing|sidebar|social|sponsors?|sponsored|tags?|widgets?)(?:$|[\s_-])`)
+	shareRegexp     = regexp.MustCompile(`(?i)^\s*(compartilh(e|ar)|share|imprimir|voltar ao topo)\b`)
+	cookieRegexp    = regexp.MustCompile(`(?i)\bcookies?\b`)
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+
+	blockElements = map[string]bool{
+		"address": true, "article": true, "blockquote": true, "dd": true, "div": true,
+		"dl": true, "dt": true, "figcaption": true, "figure": true, "h1": true, "h2": true,
+		"h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "li": true, "main": true,
+		"ol": true, "p": true, "pre": true, "section": true, "table": true, "td": true,
+		"th": true, "tr": true, "ul": true,
+	}
+)
+
+// MainContent removes boilerplate from a copy of root and returns the node
+// that holds its main content. Known content containers are used when found,
+// otherwise nodes are scored based on the paragraphs they have, similarly to
+// readability.
+func MainContent(root *goquery.Selection) *goquery.Selection {
+	doc := root.Clone()
+	RemoveBoilerplate(doc)
+
+	for _, selector := range ContentSelectors {
+		sel := doc.Find(selector).First()
+		if sel.Length() > 0 && len(strings.TrimSpace(sel.Text())) >= minContentLength {
+			return sel
+		}
+	}
+
+	if best := bestCandidate(doc); best != nil {
+		return best
+	}
+	if body := doc.Find("body"); body.Length() > 0 {
+		return body
+	}
+	return doc
+}
+
+// RemoveBoilerplate removes from sel the elements that match
+// BoilerplateSelectors, iframes that are not videos and sharing widgets and
+// cookie notices detected by their text.
+func RemoveBoilerplate(sel *goquery.Selection) {
+	for _, selector := range BoilerplateSelectors {
+		sel.Find(selector).Remove()
+	}
+	sel.Find("iframe").Each(func(_ int, s *goquery.Selection) {
+		if src, _ := s.Attr("src"); !IsVideoEmbed(src) {
+			s.Remove()
+		}
+	})
+
+	sel.Find("div, p, section, span, ul").Each(func(_ int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || len(text) > 400 {
+			return
+		}
+		if shareRegexp.MatchString(text) {
+			s.Remove()
+			return
+		}
+		if cookieRegexp.MatchString(text) && s.Find("p").Length() <= 1 && s.Find("a, button").Length() > 0 {
+			s.Remove()
+		}
+	})
+}
+
+// Text returns the text of sel with one line for each block element and
+// whitespace collapsed.
+func Text(sel *goquery.Selection) string {
+	var buf strings.Builder
+	for _, n := range sel.Nodes {
+		writeText(&buf, n)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.TrimSpace(whitespaceRegex.ReplaceAllString(line, " "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeText(buf *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(whitespaceRegex.ReplaceAllString(n.Data, " "))
+		return
+	case html.CommentNode:
+		return
+	case html.ElementNode:
+		if n.Data == "br" {
+			buf.WriteString("\n")
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && blockElements[n.Data]
+	if block {
+		buf.WriteString("\n")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(buf, c)
+	}
+	if block {
+		buf.WriteString("\n")
+	}
+}
+
+func bestCandidate(doc *goquery.Selection) *goquery.Selection {
+	scores := map[*html.Node]float64{}
+	candidates := []*goquery.Selection{}
+
+	addScore := func(sel *goquery.Selection, score float64) {
+		if sel.Length() == 0 || goquery.NodeName(sel) == "html" {
+			return
+		}
+		node := sel.Get(0)
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(sel)
+			candidates = append(candidates, sel)
+		}
+		scores[node] += score
+	}
+
+	doc.Find("p, pre, blockquote, td, div").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "div" && s.Find("p, div, table, ul, ol, pre, blockquote").Length() > 0 {
+			return
+		}
+		text := strings.TrimSpace(s.Text())
+		if len(text) < 25 {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		parent := s.Parent()
+		addScore(parent, score)
+		addScore(parent.Parent(), score/2)
+	})
+
+	var (
+		best      *goquery.Selection
+		bestScore float64
+	)
+	for _, c := range candidates {
+		score := scores[c.Get(0)] * (1 - LinkDensity(c))
+		if best == nil || score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+func initialScore(sel *goquery.Selection) float64 {
+	score := 0.0
+	switch goquery.NodeName(sel) {
+	case "article", "main":
+		score += 10
+	case "div":
+		score += 5
+	case "pre", "td", "blockquote":
+		score += 3
+	case "form", "ol", "ul", "dl", "li":
+		score -= 3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score -= 5
+	}
+
+	// Boilerplate is matched on whole words of class and id names, which are
+	// separated by spaces, dashes and underscores, so that names like "stage"
+	// and "metadados" are not mistaken for tags and metadata widgets
+	for _, attr := range []string{"class", "id"} {
+		val, _ := sel.Attr(attr)
+		if val == "" {
+			continue
+		}
+		if negativeRegexp.MatchString(val) {
+			score -= 25
+		}
+		if positiveRegexp.MatchString(val) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// LinkDensity returns the share of the text of sel that is within links.
+func LinkDensity(sel *goquery.Selection) float64 {
+	textLength := len(strings.TrimSpace(sel.Text()))
+	if textLength == 0 {
+		return 0
+	}
+	linkLength := 0
+	sel.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkLength += len(strings.TrimSpace(a.Text()))
+	})
+	return float64(linkLength) / float64(textLength)
+}
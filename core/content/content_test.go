@@ -0,0 +1,107 @@
+package content_test
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	. "github.com/fgrehm/brinfo/core/content"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const paragraph = "O governo do estado anunciou nesta segunda-feira, em coletiva de imprensa, um novo pacote de investimentos para a saúde, a educação e a segurança pública."
+
+func parse(html string) *goquery.Selection {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	Expect(err).NotTo(HaveOccurred())
+	return doc.Selection
+}
+
+var _ = Describe("MainContent", func() {
+	It("uses known content containers", func() {
+		root := parse(`<html><body>
+			<div id="portal-column-content">
+				<h1>Título</h1>
+				<div id="parent-fieldname-text"><p>` + paragraph + `</p><p>Segundo parágrafo.</p></div>
+				<div class="outros"><p>` + paragraph + `</p></div>
+			</div>
+		</body></html>`)
+
+		Expect(Text(MainContent(root))).To(Equal(paragraph + "\nSegundo parágrafo."))
+	})
+
+	It("ignores known containers without enough text", func() {
+		root := parse(`<html><body>
+			<div class="entry-content"><p>Curto.</p></div>
+			<div class="texto"><p>` + paragraph + `</p><p>` + paragraph + `</p></div>
+		</body></html>`)
+
+		Expect(Text(MainContent(root))).To(Equal(paragraph + "\n" + paragraph))
+	})
+
+	It("scores the nodes of the page when no known container is found", func() {
+		root := parse(`<html><body>
+			<div id="menu-lateral"><ul><li><a href="/a">Página inicial</a></li><li><a href="/b">Notícias do governo do estado</a></li></ul></div>
+			<div class="conteudo">
+				<p>` + paragraph + `</p>
+				<p>` + paragraph + `</p>
+			</div>
+			<div class="rodape"><p>Secretaria de Comunicação, Rua Principal, 100, Centro.</p></div>
+		</body></html>`)
+
+		Expect(Text(MainContent(root))).To(Equal(paragraph + "\n" + paragraph))
+	})
+
+	It("only penalizes class and id names that are boilerplate", func() {
+		for _, class := range []string{"stage", "metadados", "metade-superior", "navegador"} {
+			root := parse(`<html><body>
+				<div class="` + class + `"><p>` + paragraph + `</p><p>` + paragraph + `</p></div>
+				<div class="resumo"><p>` + paragraph + `</p></div>
+			</body></html>`)
+			Expect(Text(MainContent(root))).To(Equal(paragraph+"\n"+paragraph), class)
+		}
+
+		root := parse(`<html><body>
+			<div class="lista-tags"><p>` + paragraph + `</p><p>` + paragraph + `</p></div>
+			<div class="resumo"><p>` + paragraph + `</p></div>
+		</body></html>`)
+		Expect(Text(MainContent(root))).To(Equal(paragraph))
+	})
+
+	It("removes boilerplate from the main content", func() {
+		root := parse(`<html><body>
+			<header><nav><a href="/">Início</a></nav></header>
+			<article>
+				<div class="compartilhe"><a href="#">Facebook</a><a href="#">Twitter</a></div>
+				<p>` + paragraph + `</p>
+				<div class="redes"><span>Compartilhe:</span><a href="#">WhatsApp</a></div>
+				<p>` + paragraph + `</p>
+				<div class="noticias-relacionadas"><h3>Leia também</h3><a href="/x">Outra notícia</a></div>
+				<script>var x = 1;</script>
+			</article>
+			<div class="aviso"><p>Este site usa cookies para melhorar sua experiência.</p><a href="#">Aceitar</a></div>
+			<footer>Governo do Estado</footer>
+		</body></html>`)
+
+		Expect(Text(MainContent(root))).To(Equal(paragraph + "\n" + paragraph))
+	})
+
+	It("does not change the document provided", func() {
+		root := parse(`<html><body><nav>Menu</nav><article><p>` + paragraph + `</p></article></body></html>`)
+
+		MainContent(root)
+
+		Expect(root.Find("nav").Length()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Text", func() {
+	It("breaks lines on block elements and collapses whitespace", func() {
+		root := parse(`<div><h2>Subtítulo</h2><p>Primeira   linha
+			continua <strong>aqui</strong>.</p><ul><li>Item 1</li><li>Item 2</li></ul>Texto<br>solto</div>`)
+
+		Expect(Text(root.Find("div"))).To(Equal("Subtítulo\nPrimeira linha continua aqui.\nItem 1\nItem 2\nTexto\nsolto"))
+	})
+})
@@ -0,0 +1,98 @@
+package content
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DocumentTypes maps the extensions of the documents commonly attached to
+// government notes to their MIME types.
+var DocumentTypes = map[string]string{
+	"csv":  "text/csv",
+	"doc":  "application/msword",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"odp":  "application/vnd.oasis.opendocument.presentation",
+	"ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	"odt":  "application/vnd.oasis.opendocument.text",
+	"pdf":  "application/pdf",
+	"ppt":  "application/vnd.ms-powerpoint",
+	"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"rar":  "application/vnd.rar",
+	"rtf":  "application/rtf",
+	"xls":  "application/vnd.ms-excel",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"zip":  "application/zip",
+}
+
+// fileViews are the path segments that follow the name of files on the pages
+// that show or download them.
+var fileViews = map[string]bool{
+	"view": true, "download": true, "@@download": true, "file": true, "at_download": true,
+}
+
+// DocumentLink is a link to a document found on the content of a page.
+type DocumentLink struct {
+	URL       string
+	Text      string
+	Extension string
+	MIMEType  string
+}
+
+// DocumentLinks returns the links to documents found on sel, with URLs
+// resolved against base. Each URL is returned only once.
+func DocumentLinks(sel *goquery.Selection, base *url.URL) []DocumentLink {
+	links := []DocumentLink{}
+	seen := map[string]bool{}
+
+	sel.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		link := resolve(strings.TrimSpace(href), base)
+		if link == "" || seen[link] {
+			return
+		}
+		ext := DocumentExtension(link)
+		if ext == "" {
+			return
+		}
+
+		seen[link] = true
+		text := strings.TrimSpace(whitespaceRegex.ReplaceAllString(a.Text(), " "))
+		if text == "" {
+			text, _ = a.Attr("title")
+		}
+		links = append(links, DocumentLink{
+			URL:       link,
+			Text:      text,
+			Extension: ext,
+			MIMEType:  DocumentTypes[ext],
+		})
+	})
+
+	return links
+}
+
+// DocumentExtension returns the extension of the document link points to or
+// an empty string if it is not a known document type. Links to the view and
+// download pages of Plone files, like `decreto.pdf/view` and
+// `decreto.pdf/@@download/file`, are recognized as well.
+func DocumentExtension(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(segments[i]), "."))
+		if _, ok := DocumentTypes[ext]; ok {
+			return ext
+		}
+		if !fileViews[segments[i]] {
+			break
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package content_test
+
+import (
+	"net/url"
+
+	. "github.com/fgrehm/brinfo/core/content"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DocumentLinks", func() {
+	It("returns links to documents with absolute URLs", func() {
+		base, err := url.Parse("https://www.saude.es.gov.br/Not%C3%ADcia/boletim")
+		Expect(err).NotTo(HaveOccurred())
+		root := parse(`<div>
+			<p>Confira o <a href="/Media/sesa/Boletim%2012.pdf">boletim completo</a> e a
+			<a href="https://www.es.gov.br/planilha.XLSX">planilha   de casos</a>.</p>
+			<a href="/Media/sesa/Boletim%2012.pdf">Baixar</a>
+			<a href="/noticias/outra">Outra notícia</a>
+			<a href="decreto.docx" title="Decreto 4.600"><img src="icone.png"></a>
+		</div>`)
+
+		Expect(DocumentLinks(root, base)).To(Equal([]DocumentLink{
+			{URL: "https://www.saude.es.gov.br/Media/sesa/Boletim%2012.pdf", Text: "boletim completo", Extension: "pdf", MIMEType: "application/pdf"},
+			{URL: "https://www.es.gov.br/planilha.XLSX", Text: "planilha de casos", Extension: "xlsx", MIMEType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+			{URL: "https://www.saude.es.gov.br/Not%C3%ADcia/decreto.docx", Text: "Decreto 4.600", Extension: "docx", MIMEType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		}))
+	})
+})
+
+var _ = Describe("DocumentExtension", func() {
+	cases := []struct {
+		link string
+		ext  string
+	}{
+		{"https://www.gov.br/saude/decreto.pdf", "pdf"},
+		{"https://www.gov.br/saude/decreto.pdf?download=1", "pdf"},
+		{"https://www.gov.br/saude/decreto.pdf/view", "pdf"},
+		{"https://www.gov.br/saude/boletim.ods/@@download/file", "ods"},
+		{"https://www.gov.br/saude/boletim.csv/at_download/file", "csv"},
+		{"https://www.gov.br/saude/noticia.html", ""},
+		{"https://www.gov.br/saude/pdf", ""},
+		{"https://www.gov.br/saude/decreto.pdf/outra-pagina", ""},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("returns '"+c.ext+"' for "+c.link, func() {
+			Expect(DocumentExtension(c.link)).To(Equal(c.ext))
+		})
+	}
+})
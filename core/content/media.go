@@ -0,0 +1,197 @@
+package content
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Types of media found on pages.
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// minImageSize is the minimum width or height of images for them to be
+// considered part of the content instead of icons or decoration.
+const minImageSize = 100
+
+var (
+	videoEmbedRegexp    = regexp.MustCompile(`(?i)^(https?:)?//(www\.)?(youtube\.com/embed/|youtube-nocookie\.com/embed/|player\.vimeo\.com/video/|facebook\.com/plugins/video|dailymotion\.com/embed/)`)
+	decorativeSrcRegexp = regexp.MustCompile(`(?i)(icon|logo|sprite|emoji|spacer|pixel|blank|loading|avatar)[^/]*$|\.svg(\?|$)`)
+	creditRegexp        = regexp.MustCompile(`(?i)[(\[]?\s*\b(fotos?|imagem|imagens|cr[ée]ditos?|photo)\s*:\s*([^)\]]+?)\s*[)\]]?\s*$`)
+	captionSelectors    = `figcaption, .wp-caption-text, .wp-element-caption, .caption, .legenda, .image-caption`
+	creditSelectors     = `.credito, .credit, .creditos, .credits, .image-credit, .foto-credito`
+)
+
+// Media is an image or a video found on the content of a page.
+type Media struct {
+	Type    string
+	URL     string
+	Caption string
+	Credit  string
+	Alt     string
+	Width   int
+	Height  int
+}
+
+// FindMedia returns the images and embedded videos found on sel with URLs
+// resolved against base, leaving out icons and images too small to be part of
+// the content. Captions and credits are taken from the figures that wrap
+// images, with credits like "Foto: Fulano/Secom" split from the caption.
+func FindMedia(sel *goquery.Selection, base *url.URL) []Media {
+	media := []Media{}
+	seen := map[string]bool{}
+	add := func(m Media) {
+		if m.URL == "" || seen[m.URL] {
+			return
+		}
+		seen[m.URL] = true
+		media = append(media, m)
+	}
+
+	sel.Find("img, iframe, video").Each(func(_ int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "img":
+			if m, ok := image(s, base); ok {
+				add(m)
+			}
+		case "iframe":
+			src := resolve(sourceURL(s), base)
+			if IsVideoEmbed(src) {
+				add(Media{Type: MediaVideo, URL: src, Caption: strings.TrimSpace(attrOf(s, "title")), Width: intAttr(s, "width"), Height: intAttr(s, "height")})
+			}
+		case "video":
+			src := sourceURL(s)
+			if src == "" {
+				src, _ = s.Find("source[src]").First().Attr("src")
+			}
+			m := Media{Type: MediaVideo, URL: resolve(strings.TrimSpace(src), base), Width: intAttr(s, "width"), Height: intAttr(s, "height")}
+			m.Caption, m.Credit = caption(s)
+			add(m)
+		}
+	})
+
+	return media
+}
+
+// IsVideoEmbed tells if src is the URL of a video player from a known video
+// platform.
+func IsVideoEmbed(src string) bool {
+	return videoEmbedRegexp.MatchString(src)
+}
+
+// LeadImage returns the image that best represents the content, which is the
+// largest one with known dimensions or the first one when no dimensions are
+// known.
+func LeadImage(media []Media) (Media, bool) {
+	var (
+		lead     Media
+		found    bool
+		bestArea int
+	)
+	for _, m := range media {
+		if m.Type != MediaImage {
+			continue
+		}
+		if !found {
+			lead, found = m, true
+		}
+		if area := m.Width * m.Height; area > bestArea {
+			lead, bestArea = m, area
+		}
+	}
+	return lead, found
+}
+
+func image(s *goquery.Selection, base *url.URL) (Media, bool) {
+	src := resolve(sourceURL(s), base)
+	if src == "" || strings.HasPrefix(src, "data:") || decorativeSrcRegexp.MatchString(src) {
+		return Media{}, false
+	}
+
+	m := Media{
+		Type:   MediaImage,
+		URL:    src,
+		Alt:    strings.TrimSpace(attrOf(s, "alt")),
+		Width:  intAttr(s, "width"),
+		Height: intAttr(s, "height"),
+	}
+	if (m.Width > 0 && m.Width < minImageSize) || (m.Height > 0 && m.Height < minImageSize) {
+		return Media{}, false
+	}
+	m.Caption, m.Credit = caption(s)
+	if m.Credit == "" && m.Alt != "" {
+		if match := creditRegexp.FindStringSubmatch(m.Alt); match != nil {
+			m.Credit = strings.TrimSpace(match[2])
+		}
+	}
+	return m, true
+}
+
+// caption looks for the caption of s on the figure or caption container that
+// wraps it, returning the credit separately.
+func caption(s *goquery.Selection) (string, string) {
+	container := s.Closest(`figure, .wp-caption, .image, .imagem, .foto`)
+	if container.Length() == 0 {
+		return "", ""
+	}
+
+	credit := ""
+	if el := container.Find(creditSelectors).First(); el.Length() > 0 {
+		credit = collapse(el.Text())
+		credit = creditRegexp.ReplaceAllString(credit, "$2")
+	}
+
+	text := ""
+	if el := container.Find(captionSelectors).First(); el.Length() > 0 {
+		clone := el.Clone()
+		clone.Find(creditSelectors).Remove()
+		text = collapse(clone.Text())
+	}
+	if match := creditRegexp.FindStringSubmatchIndex(text); match != nil {
+		if credit == "" {
+			credit = strings.TrimSpace(text[match[4]:match[5]])
+		}
+		text = strings.TrimRight(strings.TrimSpace(text[:match[0]]), " -–|")
+	}
+	return text, credit
+}
+
+// sourceURL returns the URL of the image or video, including the ones that
+// are loaded lazily.
+func sourceURL(s *goquery.Selection) string {
+	for _, attr := range []string{"data-src", "data-lazy-src", "data-original", "src"} {
+		if val := strings.TrimSpace(attrOf(s, attr)); val != "" && !strings.HasPrefix(val, "data:") {
+			return val
+		}
+	}
+	if srcset := attrOf(s, "srcset"); srcset != "" {
+		candidates := strings.Split(srcset, ",")
+		fields := strings.Fields(candidates[len(candidates)-1])
+		if len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+func attrOf(s *goquery.Selection, name string) string {
+	val, _ := s.Attr(name)
+	return val
+}
+
+func intAttr(s *goquery.Selection, name string) int {
+	val, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(attrOf(s, name)), "px"))
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
+func collapse(text string) string {
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(text, " "))
+}
@@ -0,0 +1,122 @@
+package content_test
+
+import (
+	"net/url"
+
+	. "github.com/fgrehm/brinfo/core/content"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FindMedia", func() {
+	var base *url.URL
+
+	BeforeEach(func() {
+		var err error
+		base, err = url.Parse("https://www.es.gov.br/Noticia/vacinacao")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns images with their captions and credits", func() {
+		root := parse(`<div>
+			<figure class="wp-caption">
+				<img src="/Media/vacina.jpg" alt="Profissional aplica vacina" width="800" height="533">
+				<figcaption>Vacinação no Centro de Vitória  Foto: Fulano de Tal/Secom</figcaption>
+			</figure>
+			<div class="imagem">
+				<img data-src="/Media/posto.jpg" src="data:image/gif;base64,R0lGODlh">
+				<span class="legenda">Posto de saúde <span class="credito">Crédito: Sesa</span></span>
+			</div>
+			<p><img src="https://outro.site/ponte.jpg" alt="Ponte (Foto: Beltrano)"></p>
+		</div>`)
+
+		Expect(FindMedia(root, base)).To(Equal([]Media{
+			{Type: MediaImage, URL: "https://www.es.gov.br/Media/vacina.jpg", Caption: "Vacinação no Centro de Vitória", Credit: "Fulano de Tal/Secom", Alt: "Profissional aplica vacina", Width: 800, Height: 533},
+			{Type: MediaImage, URL: "https://www.es.gov.br/Media/posto.jpg", Caption: "Posto de saúde", Credit: "Sesa"},
+			{Type: MediaImage, URL: "https://outro.site/ponte.jpg", Alt: "Ponte (Foto: Beltrano)", Credit: "Beltrano"},
+		}))
+	})
+
+	It("leaves icons and small images out", func() {
+		root := parse(`<div>
+			<img src="/imagens/logo-governo.png">
+			<img src="/imagens/whatsapp.svg">
+			<img src="/imagens/seta.png" width="16" height="16">
+			<img src="/imagens/foto.jpg">
+			<img src="/imagens/foto.jpg">
+		</div>`)
+
+		Expect(FindMedia(root, base)).To(Equal([]Media{
+			{Type: MediaImage, URL: "https://www.es.gov.br/imagens/foto.jpg"},
+		}))
+	})
+
+	It("uses the largest candidate of srcset when there is no src", func() {
+		root := parse(`<div><img srcset="/foto-300.jpg 300w, /foto-1024.jpg 1024w"></div>`)
+
+		Expect(FindMedia(root, base)).To(Equal([]Media{
+			{Type: MediaImage, URL: "https://www.es.gov.br/foto-1024.jpg"},
+		}))
+	})
+
+	It("returns embedded videos", func() {
+		root := parse(`<div>
+			<iframe src="https://www.youtube.com/embed/abc123" title="Coletiva de imprensa" width="560" height="315"></iframe>
+			<iframe src="https://www.google.com/maps/embed?pb=1"></iframe>
+			<video width="640"><source src="/videos/boletim.mp4" type="video/mp4"></video>
+		</div>`)
+
+		Expect(FindMedia(root, base)).To(Equal([]Media{
+			{Type: MediaVideo, URL: "https://www.youtube.com/embed/abc123", Caption: "Coletiva de imprensa", Width: 560, Height: 315},
+			{Type: MediaVideo, URL: "https://www.es.gov.br/videos/boletim.mp4", Width: 640},
+		}))
+	})
+})
+
+var _ = Describe("IsVideoEmbed", func() {
+	cases := []struct {
+		src      string
+		expected bool
+	}{
+		{"https://www.youtube.com/embed/abc123", true},
+		{"//www.youtube-nocookie.com/embed/abc123", true},
+		{"https://player.vimeo.com/video/123", true},
+		{"https://www.facebook.com/plugins/video.php?href=x", true},
+		{"https://www.google.com/maps/embed?pb=1", false},
+		{"https://www.youtube.com/watch?v=abc123", false},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("handles "+c.src, func() {
+			Expect(IsVideoEmbed(c.src)).To(Equal(c.expected))
+		})
+	}
+})
+
+var _ = Describe("LeadImage", func() {
+	It("returns the largest image", func() {
+		lead, ok := LeadImage([]Media{
+			{Type: MediaImage, URL: "small.jpg", Width: 200, Height: 150},
+			{Type: MediaVideo, URL: "video", Width: 1920, Height: 1080},
+			{Type: MediaImage, URL: "large.jpg", Width: 800, Height: 600},
+		})
+		Expect(ok).To(BeTrue())
+		Expect(lead.URL).To(Equal("large.jpg"))
+	})
+
+	It("returns the first image when dimensions are unknown", func() {
+		lead, ok := LeadImage([]Media{
+			{Type: MediaImage, URL: "first.jpg"},
+			{Type: MediaImage, URL: "second.jpg"},
+		})
+		Expect(ok).To(BeTrue())
+		Expect(lead.URL).To(Equal("first.jpg"))
+	})
+
+	It("returns false without images", func() {
+		_, ok := LeadImage([]Media{{Type: MediaVideo, URL: "video"}})
+		Expect(ok).To(BeFalse())
+	})
+})
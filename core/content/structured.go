@@ -0,0 +1,381 @@
+package content
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// allowedAttributes lists the elements kept by HTML along with the attributes
+// kept for each of them. Other elements are replaced by their children.
+var allowedAttributes = map[string][]string{
+	"a": {"href", "title"}, "b": nil, "blockquote": nil, "br": nil, "code": nil,
+	"em": nil, "figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil,
+	"h4": nil, "h5": nil, "h6": nil, "hr": nil, "i": nil, "img": {"src", "alt", "title"},
+	"li": nil, "ol": nil, "p": nil, "pre": nil, "strong": nil, "sub": nil, "sup": nil,
+	"table": nil, "tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+	"th": {"colspan", "rowspan"}, "thead": nil, "tr": nil, "u": nil, "ul": nil,
+}
+
+var (
+	voidElements = map[string]bool{"br": true, "hr": true, "img": true}
+	// containerElements get a line break after being opened and closed while
+	// lineElements only after being closed, so that the HTML is readable.
+	containerElements = map[string]bool{
+		"blockquote": true, "figure": true, "ol": true, "table": true, "tbody": true,
+		"tfoot": true, "thead": true, "ul": true,
+	}
+	lineElements = map[string]bool{
+		"figcaption": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+		"h6": true, "hr": true, "li": true, "p": true, "pre": true, "tr": true,
+	}
+
+	markdownEscapeRegexp = regexp.MustCompile("([\\\\`*_\\[\\]])")
+	blankLinesRegexp     = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTML returns a sanitized copy of the contents of sel, keeping only
+// paragraphs, headings, lists, tables, quotes, links and images. Links and
+// images are resolved against base when it is set.
+func HTML(sel *goquery.Selection, base *url.URL) string {
+	var buf strings.Builder
+	for _, n := range sel.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeHTML(&buf, c, base, false)
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func writeHTML(buf *strings.Builder, n *html.Node, base *url.URL, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		text := n.Data
+		if !pre {
+			if strings.TrimSpace(text) == "" && !betweenInlines(n) {
+				return
+			}
+			text = whitespaceRegex.ReplaceAllString(text, " ")
+		}
+		buf.WriteString(html.EscapeString(text))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	attrs, allowed := allowedAttributes[n.Data]
+	if n.Data == "a" && resolve(attr(n, "href"), base) == "" {
+		allowed = false
+	}
+	if !allowed {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeHTML(buf, c, base, pre)
+		}
+		return
+	}
+	if !voidElements[n.Data] && n.Data != "td" && n.Data != "th" && isEmpty(n) {
+		return
+	}
+	if n.Data == "img" && resolve(imageSource(n), base) == "" {
+		return
+	}
+
+	buf.WriteString("<" + n.Data)
+	for _, name := range attrs {
+		val := attr(n, name)
+		if name == "src" {
+			val = imageSource(n)
+		}
+		if name == "href" || name == "src" {
+			val = resolve(val, base)
+		}
+		if val != "" {
+			buf.WriteString(" " + name + `="` + html.EscapeString(val) + `"`)
+		}
+	}
+	buf.WriteString(">")
+	if containerElements[n.Data] {
+		buf.WriteString("\n")
+	}
+	if voidElements[n.Data] {
+		if lineElements[n.Data] {
+			buf.WriteString("\n")
+		}
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeHTML(buf, c, base, pre || n.Data == "pre")
+	}
+	buf.WriteString("</" + n.Data + ">")
+	if containerElements[n.Data] || lineElements[n.Data] {
+		buf.WriteString("\n")
+	}
+}
+
+// Markdown returns the contents of sel as Markdown, with the same elements
+// kept by HTML.
+func Markdown(sel *goquery.Selection, base *url.URL) string {
+	blocks := []string{}
+	for _, n := range sel.Nodes {
+		blocks = append(blocks, markdownBlocks(n, base)...)
+	}
+	md := strings.Join(blocks, "\n\n")
+	return strings.TrimSpace(blankLinesRegexp.ReplaceAllString(md, "\n\n"))
+}
+
+// markdownBlocks renders the children of n, grouping inline content into
+// paragraphs.
+func markdownBlocks(n *html.Node, base *url.URL) []string {
+	blocks := []string{}
+	var inline strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(inline.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && isBlock(c) {
+			flush()
+			blocks = append(blocks, markdownBlock(c, base)...)
+			continue
+		}
+		inline.WriteString(markdownInline(c, base))
+	}
+	flush()
+	return blocks
+}
+
+func markdownBlock(n *html.Node, base *url.URL) []string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := inlineText(n, base)
+		if text == "" {
+			return nil
+		}
+		return []string{strings.Repeat("#", int(n.Data[1]-'0')) + " " + text}
+	case "p", "figcaption":
+		if text := inlineText(n, base); text != "" {
+			return []string{text}
+		}
+		return nil
+	case "ul", "ol":
+		if list := markdownList(n, base); list != "" {
+			return []string{list}
+		}
+		return nil
+	case "blockquote":
+		inner := strings.Join(markdownBlocks(n, base), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", "> ")}
+	case "pre":
+		text := strings.Trim(goquery.NewDocumentFromNode(n).Text(), "\n")
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return []string{"```\n" + text + "\n```"}
+	case "table":
+		if table := markdownTable(n, base); table != "" {
+			return []string{table}
+		}
+		return nil
+	case "hr":
+		return []string{"---"}
+	default:
+		return markdownBlocks(n, base)
+	}
+}
+
+func markdownList(n *html.Node, base *url.URL) string {
+	items := []string{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+		text := strings.Join(markdownBlocks(c, base), "\n")
+		if text == "" {
+			continue
+		}
+		marker := "- "
+		if n.Data == "ol" {
+			marker = strconv.Itoa(len(items)+1) + ". "
+		}
+		items = append(items, prefixLines(text, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+func markdownTable(n *html.Node, base *url.URL) string {
+	rows := [][]string{}
+	goquery.NewDocumentFromNode(n).Find("tr").Each(func(_ int, tr *goquery.Selection) {
+		row := []string{}
+		tr.Children().Each(func(_ int, cell *goquery.Selection) {
+			text := inlineText(cell.Get(0), base)
+			row = append(row, strings.Replace(strings.Replace(text, "|", "\\|", -1), "\n", " ", -1))
+		})
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	lines := []string{}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownInline(n *html.Node, base *url.URL) string {
+	switch n.Type {
+	case html.TextNode:
+		return markdownEscapeRegexp.ReplaceAllString(whitespaceRegex.ReplaceAllString(n.Data, " "), `\$1`)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "img":
+		src := resolve(imageSource(n), base)
+		if src == "" {
+			return ""
+		}
+		return "![" + markdownEscapeRegexp.ReplaceAllString(attr(n, "alt"), `\$1`) + "](" + src + ")"
+	}
+
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		buf.WriteString(markdownInline(c, base))
+	}
+	text := buf.String()
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	wrap := func(prefix, suffix string) string {
+		leading := text[:strings.Index(text, trimmed)]
+		trailing := text[len(leading)+len(trimmed):]
+		return leading + prefix + trimmed + suffix + trailing
+	}
+	switch n.Data {
+	case "a":
+		if href := resolve(attr(n, "href"), base); href != "" {
+			return wrap("[", "]("+href+")")
+		}
+	case "strong", "b":
+		return wrap("**", "**")
+	case "em", "i":
+		return wrap("_", "_")
+	case "code":
+		return wrap("`", "`")
+	}
+	return text
+}
+
+// inlineText renders the children of n as a single paragraph, with lines
+// trimmed.
+func inlineText(n *html.Node, base *url.URL) string {
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		buf.WriteString(markdownInline(c, base))
+	}
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = first + line
+		} else if line != "" {
+			lines[i] = rest + line
+		} else {
+			lines[i] = strings.TrimRight(rest, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isBlock(n *html.Node) bool {
+	return blockElements[n.Data] || n.Data == "hr"
+}
+
+// betweenInlines tells if a whitespace only text node separates inline
+// content, in which case it needs to be kept.
+func betweenInlines(n *html.Node) bool {
+	prev, next := n.PrevSibling, n.NextSibling
+	if prev == nil || next == nil {
+		return false
+	}
+	return !(prev.Type == html.ElementNode && isBlock(prev)) &&
+		!(next.Type == html.ElementNode && isBlock(next))
+}
+
+func isEmpty(n *html.Node) bool {
+	sel := goquery.NewDocumentFromNode(n).Selection
+	return strings.TrimSpace(sel.Text()) == "" && sel.Find("img").Length() == 0
+}
+
+func imageSource(n *html.Node) string {
+	return sourceURL(goquery.NewDocumentFromNode(n).Selection)
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// resolve makes link absolute based on base, returning an empty string for
+// javascript links and fragments.
+func resolve(link string, base *url.URL) string {
+	if link == "" || strings.HasPrefix(link, "#") {
+		return ""
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto" {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
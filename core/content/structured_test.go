@@ -0,0 +1,94 @@
+package content_test
+
+import (
+	"net/url"
+
+	. "github.com/fgrehm/brinfo/core/content"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Structured output", func() {
+	var base *url.URL
+
+	BeforeEach(func() {
+		var err error
+		base, err = url.Parse("https://www.es.gov.br/noticias/artigo")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	body := `<div class="entry-content" style="color: red">
+		<h2 class="titulo">Grupos <em>prioritários</em></h2>
+		<p>Texto com <a href="/vacinas" onclick="track()">link</a> e <strong>destaque</strong>.</p>
+		<p></p>
+		<ul>
+			<li>Idosos</li>
+			<li>Gestantes<ol><li>Primeira dose</li></ol></li>
+		</ul>
+		<table>
+			<tr><th>Município</th><th>Doses</th></tr>
+			<tr><td>Vitória</td><td>1.200</td></tr>
+		</table>
+		<figure><img src="img/foto.jpg" alt="Foto"><figcaption>Legenda da foto</figcaption></figure>
+		<blockquote><p>Uma citação</p></blockquote>
+		<div><span>Texto solto</span> com <a href="javascript:void(0)">link inválido</a></div>
+	</div>`
+
+	It("renders sanitized HTML", func() {
+		root := parse(body).Find(".entry-content")
+
+		Expect(HTML(root, base)).To(Equal(`<h2>Grupos <em>prioritários</em></h2>
+<p>Texto com <a href="https://www.es.gov.br/vacinas">link</a> e <strong>destaque</strong>.</p>
+<ul>
+<li>Idosos</li>
+<li>Gestantes<ol>
+<li>Primeira dose</li>
+</ol>
+</li>
+</ul>
+<table>
+<tbody>
+<tr><th>Município</th><th>Doses</th></tr>
+<tr><td>Vitória</td><td>1.200</td></tr>
+</tbody>
+</table>
+<figure>
+<img src="https://www.es.gov.br/noticias/img/foto.jpg" alt="Foto"><figcaption>Legenda da foto</figcaption>
+</figure>
+<blockquote>
+<p>Uma citação</p>
+</blockquote>
+Texto solto com link inválido`))
+	})
+
+	It("renders Markdown", func() {
+		root := parse(body).Find(".entry-content")
+
+		Expect(Markdown(root, base)).To(Equal(`## Grupos _prioritários_
+
+Texto com [link](https://www.es.gov.br/vacinas) e **destaque**.
+
+- Idosos
+- Gestantes
+  1. Primeira dose
+
+| Município | Doses |
+| --- | --- |
+| Vitória | 1.200 |
+
+![Foto](https://www.es.gov.br/noticias/img/foto.jpg)
+
+Legenda da foto
+
+> Uma citação
+
+Texto solto com link inválido`))
+	})
+
+	It("escapes Markdown characters found on the text", func() {
+		root := parse(`<div><p>Lei_14.123 [2020] *nova*</p></div>`).Find("div")
+
+		Expect(Markdown(root, nil)).To(Equal(`Lei\_14.123 \[2020\] \*nova\*`))
+	})
+})
@@ -3,8 +3,10 @@ package core
 import (
 	"context"
 	"encoding/json"
-	"net/url"
+	"reflect"
 	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
 )
 
 type ArticleScraper interface {
@@ -16,31 +18,103 @@ type ArticleListScraper interface {
 }
 
 type ArticleData struct {
-	Extra        map[string]interface{} `json:"brinfo"`
-	URL          string                 `json:"url"`
-	URLHash      string                 `json:"url_hash"`
-	Title        string                 `json:"title"`
-	FullText     string                 `json:"full_text"`
-	FullTextHash string                 `json:"full_text_hash"`
-	Excerpt      string                 `json:"excerpt"`
-	FoundAt      time.Time              `json:"found_at"`
-	PublishedAt  *time.Time             `json:"published_at"`
-	ModifiedAt   *time.Time             `json:"updated_at"` // TODO: Serialize to modified at after changing covid19br.pub
-	ImageURL     string                 `json:"image_url"`
+	Extra                 map[string]interface{} `json:"brinfo"`
+	URL                   string                 `json:"url"`
+	URLHash               string                 `json:"url_hash"`
+	CanonicalURL          string                 `json:"canonical_url,omitempty"`
+	Title                 string                 `json:"title"`
+	FullText              string                 `json:"full_text"`
+	FullTextHash          string                 `json:"full_text_hash"`
+	Fingerprint           string                 `json:"fingerprint,omitempty"`
+	FullTextHTML          string                 `json:"full_text_html,omitempty"`
+	FullTextMarkdown      string                 `json:"full_text_markdown,omitempty"`
+	Language              string                 `json:"language,omitempty"`
+	LanguageConfidence    float64                `json:"language_confidence,omitempty"`
+	WordCount             int                    `json:"word_count,omitempty"`
+	SentenceCount         int                    `json:"sentence_count,omitempty"`
+	ReadingMinutes        int                    `json:"reading_minutes,omitempty"`
+	Excerpt               string                 `json:"excerpt"`
+	FoundAt               time.Time              `json:"found_at"`
+	PublishedAt           *time.Time             `json:"published_at"`
+	PublishedAtConfidence float64                `json:"published_at_confidence,omitempty"`
+	PublishedAtPrecision  dates.Precision        `json:"published_at_precision,omitempty"`
+	ModifiedAt            *time.Time             `json:"updated_at"` // Emitted as modified_at since version 2 of the schema
+	ModifiedAtConfidence  float64                `json:"updated_at_confidence,omitempty"`
+	ModifiedAtPrecision   dates.Precision        `json:"updated_at_precision,omitempty"`
+	ImageURL              string                 `json:"image_url"`
+	Attachments           []*Attachment          `json:"attachments,omitempty"`
+	Media                 []*Media               `json:"media,omitempty"`
+}
+
+// Media is an image or a video found on the content of an article.
+type Media struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+	Credit  string `json:"credit,omitempty"`
+	Alt     string `json:"alt,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+}
+
+// Attachment is a document linked from the content of an article, like the
+// PDF of a decree or the spreadsheet of a bulletin. Size, SHA256 and Content
+// are only set when the attachment is downloaded, Content holds the gzipped
+// file.
+type Attachment struct {
+	URL       string `json:"url"`
+	Text      string `json:"text,omitempty"`
+	Extension string `json:"extension"`
+	MIMEType  string `json:"mime_type,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+	Content   []byte `json:"content,omitempty"`
+}
+
+// Types of pages assigned by the page classifier of the scrapers, which are
+// kept on the "page_type" extra field. Only articles should be ingested.
+const (
+	PageTypeArticle = "article"
+	PageTypeListing = "listing"
+	PageTypeError   = "error"
+	PageTypeOther   = "other"
+)
+
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
 }
 
 type ArticleLink struct {
-	URL         string     `json:"url"`
-	PublishedAt *time.Time `json:"published_at,omitempty"`
-	ImageURL    *string    `json:"image_url,omitempty"`
+	URL                  string          `json:"url"`
+	PublishedAt          *time.Time      `json:"published_at,omitempty"`
+	PublishedAtPrecision dates.Precision `json:"published_at_precision,omitempty"`
+	ImageURL             *string         `json:"image_url,omitempty"`
 }
 
+// ArticleDataFromJSON loads article data emitted on any version of the
+// schema, which differ on where the modified date is kept.
 func ArticleDataFromJSON(data []byte) (*ArticleData, error) {
 	articleData := &ArticleData{}
 	err := json.Unmarshal(data, &articleData)
 	if err != nil {
 		return nil, err
 	}
+
+	modified := struct {
+		ModifiedAt           *time.Time      `json:"modified_at"`
+		ModifiedAtConfidence float64         `json:"modified_at_confidence"`
+		ModifiedAtPrecision  dates.Precision `json:"modified_at_precision"`
+	}{}
+	if err = json.Unmarshal(data, &modified); err != nil {
+		return nil, err
+	}
+	if modified.ModifiedAt != nil {
+		articleData.ModifiedAt = modified.ModifiedAt
+		articleData.ModifiedAtConfidence = modified.ModifiedAtConfidence
+		articleData.ModifiedAtPrecision = modified.ModifiedAtPrecision
+	}
 	return articleData, nil
 }
 
@@ -58,12 +132,27 @@ func (d *ArticleData) CollectValues(other *ArticleData) {
 		d.URL = other.URL
 		d.URLHash = other.URLHash
 	}
+	if other.CanonicalURL != "" {
+		d.CanonicalURL = other.CanonicalURL
+	}
 	if other.Title != "" {
 		d.Title = other.Title
 	}
 	if other.FullText != "" {
 		d.FullText = other.FullText
 		d.FullTextHash = other.FullTextHash
+		d.Fingerprint = other.Fingerprint
+		d.Language = other.Language
+		d.LanguageConfidence = other.LanguageConfidence
+		d.WordCount = other.WordCount
+		d.SentenceCount = other.SentenceCount
+		d.ReadingMinutes = other.ReadingMinutes
+	}
+	if other.FullTextHTML != "" {
+		d.FullTextHTML = other.FullTextHTML
+	}
+	if other.FullTextMarkdown != "" {
+		d.FullTextMarkdown = other.FullTextMarkdown
 	}
 	if other.Excerpt != "" {
 		d.Excerpt = other.Excerpt
@@ -72,63 +161,109 @@ func (d *ArticleData) CollectValues(other *ArticleData) {
 		d.FoundAt = other.FoundAt
 	}
 	// TODO: Make sure the modified at is >= pubat
-	if other.PublishedAt != nil && !other.PublishedAt.IsZero() {
+	// Dates are kept when they are more precise than the ones being collected,
+	// like when merging a date found on a listing with the article data
+	if other.PublishedAt != nil && !other.PublishedAt.IsZero() &&
+		!(d.PublishedAt != nil && d.PublishedAtPrecision.MorePreciseThan(other.PublishedAtPrecision)) {
 		d.PublishedAt = other.PublishedAt
+		d.PublishedAtConfidence = other.PublishedAtConfidence
+		d.PublishedAtPrecision = other.PublishedAtPrecision
 	}
-	if other.ModifiedAt != nil && !other.ModifiedAt.IsZero() {
+	if other.ModifiedAt != nil && !other.ModifiedAt.IsZero() &&
+		!(d.ModifiedAt != nil && d.ModifiedAtPrecision.MorePreciseThan(other.ModifiedAtPrecision)) {
 		d.ModifiedAt = other.ModifiedAt
+		d.ModifiedAtConfidence = other.ModifiedAtConfidence
+		d.ModifiedAtPrecision = other.ModifiedAtPrecision
 	}
 	if other.ImageURL != "" {
 		d.ImageURL = other.ImageURL
 	}
-}
-
-func (d *ArticleData) ValidForIngestion() (bool, []string) {
-	errors := []string{}
-	// now := time.Now()
-
-	if d.URL == "" {
-		errors = append(errors, "missing url")
-	}
-	if d.URLHash == "" {
-		errors = append(errors, "missing url_hash")
+	if len(other.Attachments) > 0 {
+		d.Attachments = other.Attachments
 	}
-	if d.Title == "" {
-		errors = append(errors, "missing title")
+	if len(other.Media) > 0 {
+		d.Media = other.Media
 	}
-	if d.FullText == "" {
-		errors = append(errors, "missing full_text")
+}
+
+// Diff returns the fields that have different values on other, using the same
+// names as the JSON representation. Extra and FoundAt are not compared since
+// they are expected to change between runs. Attachments and media are
+// compared by their URLs, along with the hashes of the attachments that were
+// downloaded.
+func (d *ArticleData) Diff(other *ArticleData) []*FieldChange {
+	changes := []*FieldChange{}
+	diffString := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, &FieldChange{Field: field, Before: before, After: after})
+		}
 	}
-	if d.FullTextHash == "" {
-		errors = append(errors, "missing full_text_hash")
+	diffInt := func(field string, before, after int) {
+		if before != after {
+			changes = append(changes, &FieldChange{Field: field, Before: before, After: after})
+		}
 	}
-	if d.PublishedAt == nil || d.PublishedAt.IsZero() {
-		errors = append(errors, "missing published_at")
+	diffFloat := func(field string, before, after float64) {
+		if before != after {
+			changes = append(changes, &FieldChange{Field: field, Before: before, After: after})
+		}
 	}
-	if d.ModifiedAt != nil && d.ModifiedAt.IsZero() {
-		errors = append(errors, "updated_at in the future")
+	diffTime := func(field string, before, after *time.Time) {
+		if before == nil && after == nil {
+			return
+		}
+		if before != nil && after != nil && before.Equal(*after) {
+			return
+		}
+		changes = append(changes, &FieldChange{Field: field, Before: before, After: after})
 	}
 
-	now := time.Now()
-	if d.PublishedAt != nil && d.PublishedAt.Sub(now) >= (time.Hour*12) {
-		errors = append(errors, "published_at in the future")
+	diffString("url", d.URL, other.URL)
+	diffString("url_hash", d.URLHash, other.URLHash)
+	diffString("canonical_url", d.CanonicalURL, other.CanonicalURL)
+	diffString("title", d.Title, other.Title)
+	diffString("full_text", d.FullText, other.FullText)
+	diffString("full_text_hash", d.FullTextHash, other.FullTextHash)
+	diffString("fingerprint", d.Fingerprint, other.Fingerprint)
+	diffString("full_text_html", d.FullTextHTML, other.FullTextHTML)
+	diffString("full_text_markdown", d.FullTextMarkdown, other.FullTextMarkdown)
+	diffString("language", d.Language, other.Language)
+	diffFloat("language_confidence", d.LanguageConfidence, other.LanguageConfidence)
+	diffInt("word_count", d.WordCount, other.WordCount)
+	diffInt("sentence_count", d.SentenceCount, other.SentenceCount)
+	diffInt("reading_minutes", d.ReadingMinutes, other.ReadingMinutes)
+	diffString("excerpt", d.Excerpt, other.Excerpt)
+	diffTime("published_at", d.PublishedAt, other.PublishedAt)
+	diffFloat("published_at_confidence", d.PublishedAtConfidence, other.PublishedAtConfidence)
+	diffString("published_at_precision", string(d.PublishedAtPrecision), string(other.PublishedAtPrecision))
+	diffTime("updated_at", d.ModifiedAt, other.ModifiedAt)
+	diffFloat("updated_at_confidence", d.ModifiedAtConfidence, other.ModifiedAtConfidence)
+	diffString("updated_at_precision", string(d.ModifiedAtPrecision), string(other.ModifiedAtPrecision))
+	diffString("image_url", d.ImageURL, other.ImageURL)
+	if !reflect.DeepEqual(attachmentKeys(d.Attachments), attachmentKeys(other.Attachments)) {
+		changes = append(changes, &FieldChange{Field: "attachments", Before: d.Attachments, After: other.Attachments})
 	}
-	if d.ModifiedAt != nil && d.ModifiedAt.Sub(now) >= (time.Hour*12) {
-		errors = append(errors, "updated_at in the future")
+	if !reflect.DeepEqual(mediaURLs(d.Media), mediaURLs(other.Media)) {
+		changes = append(changes, &FieldChange{Field: "media", Before: d.Media, After: other.Media})
 	}
 
-	if d.FoundAt.IsZero() {
-		errors = append(errors, "missing found_at")
-	}
-	if d.ImageURL != "" {
-		u, err := url.Parse(d.ImageURL)
-		if err != nil {
-			panic(err)
-		}
-		if u.Host == "" {
-			errors = append(errors, "image_url is not absolute")
-		}
+	return changes
+}
+
+// attachmentKeys identifies attachments by their URLs and the hashes of their
+// contents, which are only known when they are downloaded.
+func attachmentKeys(attachments []*Attachment) []string {
+	keys := []string{}
+	for _, a := range attachments {
+		keys = append(keys, a.URL+" "+a.SHA256)
 	}
+	return keys
+}
 
-	return len(errors) == 0, errors
+func mediaURLs(media []*Media) []string {
+	urls := []string{}
+	for _, m := range media {
+		urls = append(urls, m.URL)
+	}
+	return urls
 }
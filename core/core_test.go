@@ -1,144 +1,121 @@
 package core_test
 
 import (
-	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	gomegat "github.com/onsi/gomega/types"
 
 	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
 )
 
 var _ = Describe("Core", func() {
 	Context("ArticleData", func() {
-		Context("ValidForIngestion", func() {
-			var data *ArticleData
-
-			BeforeEach(func() {
-				now := time.Now()
-				data = &ArticleData{
-					URL:          "https://example.com",
-					URLHash:      "url-hash",
-					Title:        "Article title",
-					FullText:     "text",
-					FullTextHash: "text-hash",
-					FoundAt:      now,
-					PublishedAt:  &now,
-					ModifiedAt:   &now,
-					ImageURL:     "http://image.url",
-				}
-
-				Expect(data).To(BeValidForIngestion())
+		Context("CollectValues", func() {
+			It("keeps dates that are more precise than the ones being collected", func() {
+				withTime := time.Date(2020, 6, 8, 14, 30, 0, 0, time.UTC)
+				dayOnly := time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC)
+
+				data := &ArticleData{PublishedAt: &withTime, PublishedAtPrecision: dates.PrecisionMinute}
+				data.CollectValues(&ArticleData{PublishedAt: &dayOnly, PublishedAtPrecision: dates.PrecisionDay})
+				Expect(*data.PublishedAt).To(Equal(withTime))
+				Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionMinute))
+
+				data = &ArticleData{PublishedAt: &dayOnly, PublishedAtPrecision: dates.PrecisionDay}
+				data.CollectValues(&ArticleData{PublishedAt: &withTime, PublishedAtPrecision: dates.PrecisionMinute})
+				Expect(*data.PublishedAt).To(Equal(withTime))
+				Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionMinute))
 			})
 
-			It("is invalid if no URL is set", func() {
-				data.URL = ""
-				Expect(data).NotTo(BeValidForIngestion())
-			})
+			It("collects dates when the precision is unknown", func() {
+				withTime := time.Date(2020, 6, 8, 14, 30, 0, 0, time.UTC)
+				other := time.Date(2020, 6, 9, 10, 0, 0, 0, time.UTC)
 
-			It("is invalid if no URLHash is set", func() {
-				data.URLHash = ""
-				Expect(data).NotTo(BeValidForIngestion())
+				data := &ArticleData{ModifiedAt: &withTime, ModifiedAtPrecision: dates.PrecisionMinute}
+				data.CollectValues(&ArticleData{ModifiedAt: &other})
+				Expect(*data.ModifiedAt).To(Equal(other))
+				Expect(data.ModifiedAtPrecision).To(BeEmpty())
 			})
 
-			It("is invalid if no Title is set", func() {
-				data.Title = ""
-				Expect(data).NotTo(BeValidForIngestion())
-			})
+			It("collects the structured full text", func() {
+				data := &ArticleData{FullText: "Text", FullTextHTML: "<p>Text</p>", FullTextMarkdown: "Text"}
+				data.CollectValues(&ArticleData{FullText: "Other text"})
+				Expect(data.FullTextHTML).To(Equal("<p>Text</p>"))
 
-			It("is invalid if no FullText is set", func() {
-				data.FullText = ""
-				Expect(data).NotTo(BeValidForIngestion())
+				data.CollectValues(&ArticleData{FullTextHTML: "<p>Other text</p>", FullTextMarkdown: "Other text"})
+				Expect(data.FullTextHTML).To(Equal("<p>Other text</p>"))
+				Expect(data.FullTextMarkdown).To(Equal("Other text"))
 			})
+		})
 
-			It("is invalid if no FullTextHash is set", func() {
-				data.FullTextHash = ""
-				Expect(data).NotTo(BeValidForIngestion())
-			})
+		Context("Diff", func() {
+			It("returns nothing when data is the same", func() {
+				now := time.Now()
+				data := &ArticleData{Title: "Title", PublishedAt: &now}
+				other := &ArticleData{Title: "Title", PublishedAt: &now, FoundAt: now}
 
-			It("is invalid if no FoundAt is set", func() {
-				var def time.Time
-				data.FoundAt = def
-				Expect(data).NotTo(BeValidForIngestion())
+				Expect(data.Diff(other)).To(BeEmpty())
 			})
 
-			It("is invalid if no PublishedAt is set", func() {
-				data.PublishedAt = nil
-				Expect(data).NotTo(BeValidForIngestion())
-
-				var def time.Time
-				data.PublishedAt = &def
-				Expect(data).NotTo(BeValidForIngestion())
+			It("returns changed fields", func() {
+				now := time.Now()
+				later := now.Add(time.Hour)
+				data := &ArticleData{Title: "Title", FullText: "Text", Language: "pt", PublishedAt: &now}
+				other := &ArticleData{Title: "New title", FullText: "Text", Language: "es", PublishedAt: &later, ModifiedAt: &later}
+
+				Expect(data.Diff(other)).To(Equal([]*FieldChange{
+					{Field: "title", Before: "Title", After: "New title"},
+					{Field: "language", Before: "pt", After: "es"},
+					{Field: "published_at", Before: &now, After: &later},
+					{Field: "updated_at", Before: (*time.Time)(nil), After: &later},
+				}))
 			})
 
-			It("is invalid if PublishedAt is in the future", func() {
-				newTime := data.PublishedAt.Add(time.Hour * 24)
-				data.PublishedAt = &newTime
-				Expect(data).NotTo(BeValidForIngestion())
-			})
+			It("compares the precision and confidence of dates", func() {
+				now := time.Now()
+				data := &ArticleData{PublishedAt: &now, PublishedAtPrecision: dates.PrecisionDay, PublishedAtConfidence: 0.5}
+				other := &ArticleData{PublishedAt: &now, PublishedAtPrecision: dates.PrecisionMinute, PublishedAtConfidence: 0.9}
 
-			It("is valid if no Excerpt is set", func() {
-				data.Excerpt = ""
-				Expect(data).To(BeValidForIngestion())
+				Expect(data.Diff(other)).To(Equal([]*FieldChange{
+					{Field: "published_at_confidence", Before: 0.5, After: 0.9},
+					{Field: "published_at_precision", Before: "day", After: "minute"},
+				}))
 			})
 
-			It("is invalid if ModifiedAt is set to default", func() {
-				var def time.Time
-				data.ModifiedAt = &def
-				Expect(data).NotTo(BeValidForIngestion())
-			})
+			It("compares the language confidence and the statistics of the text", func() {
+				data := &ArticleData{Language: "pt", LanguageConfidence: 0.8, WordCount: 120, SentenceCount: 6, ReadingMinutes: 1}
+				other := &ArticleData{Language: "pt", LanguageConfidence: 0.9, WordCount: 250, SentenceCount: 12, ReadingMinutes: 2}
 
-			It("is invalid if ModifiedAt is in the future", func() {
-				newTime := data.PublishedAt.Add(time.Hour * 24)
-				data.ModifiedAt = &newTime
-				Expect(data).NotTo(BeValidForIngestion())
+				Expect(data.Diff(other)).To(Equal([]*FieldChange{
+					{Field: "language_confidence", Before: 0.8, After: 0.9},
+					{Field: "word_count", Before: 120, After: 250},
+					{Field: "sentence_count", Before: 6, After: 12},
+					{Field: "reading_minutes", Before: 1, After: 2},
+				}))
 			})
 
-			It("is valid if no ModifiedAt is set", func() {
-				data.ModifiedAt = nil
-				Expect(data).To(BeValidForIngestion())
-			})
+			It("compares attachments by their URLs and contents", func() {
+				data := &ArticleData{Attachments: []*Attachment{{URL: "https://example.com/decreto.pdf", Text: "Decreto", SHA256: "abc"}}}
+				other := &ArticleData{Attachments: []*Attachment{{URL: "https://example.com/decreto.pdf", SHA256: "abc"}}}
+				Expect(data.Diff(other)).To(BeEmpty())
 
-			It("is valid if no ImageURL is set", func() {
-				data.ImageURL = ""
-				Expect(data).To(BeValidForIngestion())
+				other.Attachments[0].SHA256 = "def"
+				Expect(data.Diff(other)).To(Equal([]*FieldChange{
+					{Field: "attachments", Before: data.Attachments, After: other.Attachments},
+				}))
 			})
 
-			It("is valid if no ImageURL is relative", func() {
-				data.ImageURL = "/foo/bar"
-				Expect(data).NotTo(BeValidForIngestion())
+			It("compares media by their URLs", func() {
+				data := &ArticleData{Media: []*Media{{Type: "image", URL: "https://image.url", Caption: "Caption"}}}
+				other := &ArticleData{Media: []*Media{{Type: "image", URL: "https://image.url"}}}
+				Expect(data.Diff(other)).To(BeEmpty())
 
-				data.ImageURL = "fooo.com/foo/bar"
-				Expect(data).NotTo(BeValidForIngestion())
+				other.Media = append(other.Media, &Media{Type: "video", URL: "https://video.url"})
+				Expect(data.Diff(other)).To(Equal([]*FieldChange{
+					{Field: "media", Before: data.Media, After: other.Media},
+				}))
 			})
 		})
 	})
 })
-
-type validForIngestionMatcher struct{}
-
-func BeValidForIngestion() gomegat.GomegaMatcher {
-	return &validForIngestionMatcher{}
-}
-
-func (m *validForIngestionMatcher) Match(actual interface{}) (bool, error) {
-	data, ok := actual.(*ArticleData)
-	if !ok {
-		return false, fmt.Errorf("BeValidForIngestion matcher expects a pointer to core.ArticleData")
-	}
-
-	valid, _ := data.ValidForIngestion()
-	return valid, nil
-}
-
-func (m *validForIngestionMatcher) FailureMessage(actual interface{}) string {
-	data, _ := actual.(*ArticleData)
-	_, errMsgs := data.ValidForIngestion()
-	return fmt.Sprintf("Expected data to be valid for ingestion but it is not: %v", errMsgs)
-}
-
-func (m *validForIngestionMatcher) NegatedFailureMessage(actual interface{}) string {
-	return "Expected data to be invalid for ingestion"
-}
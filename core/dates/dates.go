@@ -0,0 +1,277 @@
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+// DefaultLocation is used for interpreting times that don't have an offset
+// when no location is provided.
+var DefaultLocation *time.Location
+
+func init() {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+	DefaultLocation = loc
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+// Parser parses dates written the way Brazilian government websites usually
+// write them, falling back to dateparse for everything else. Relative
+// expressions like "há 3 horas" and "ontem" are resolved using Clock.
+type Parser struct {
+	Location *time.Location
+	Clock    Clock
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+var (
+	monthsPattern = `janeiro|fevereiro|março|marco|abril|maio|junho|julho|agosto|setembro|outubro|novembro|dezembro|jan|fev|mar|abr|mai|jun|jul|ago|set|out|nov|dez`
+	timePattern   = `(?:\s*(?:,|-|–|às|as|a partir das)?\s*(\d{1,2})\s*[h:]\s*(\d{2})?(?:\s*(?:min|m|:)\s*(\d{2})\s*s?)?(?:\s*(am|pm)\b)?)?`
+
+	// Years with 2 digits are only accepted with slashes since dots and dashes
+	// are also used by version and phone numbers
+	numericDateRegexp = regexp.MustCompile(`(?i)\b(\d{1,2})(?:/(\d{1,2})/(\d{4}|\d{2})|[.-](\d{1,2})[.-](\d{4}))\b` + timePattern)
+	longDateRegexp    = regexp.MustCompile(`(?i)\b(\d{1,2})º?(?:\s+de\s+|\s+|/|-)(` + monthsPattern + `)\.?(?:\s+de\s+|\s+|/|-|,\s*)(\d{4})\b` + timePattern)
+	agoRegexp         = regexp.MustCompile(`(?i)\bh[áa]\s+(\d+)\s+(minutos?|min|horas?|h|dias?|semanas?|m[êe]s|meses)\b`)
+	relativeDayRegexp = regexp.MustCompile(`(?i)\b(anteontem|ontem|hoje)\b` + timePattern)
+
+	// englishDateRegexp matches RFC 1123 / RFC 822 style dates, which are
+	// left to dateparse so that their offsets are not lost.
+	englishDateRegexp = regexp.MustCompile(`^(?i:mon|tue|wed|thu|fri|sat|sun)[a-zA-Z]*,?\s|(?:[+-]\d{2}:?\d{2}|\s(?:UTC|GMT|[A-Z]{2,3}[SD]?T))$`)
+
+	yearOnlyRegexp  = regexp.MustCompile(`^\d{4}$`)
+	monthOnlyRegexp = regexp.MustCompile(`^(?:\d{4}-\d{1,2}|\d{1,2}/\d{4})$`)
+	secondsRegexp   = regexp.MustCompile(`\d{1,2}:\d{2}:\d{2}`)
+	minutesRegexp   = regexp.MustCompile(`\d{1,2}:\d{2}`)
+
+	months = map[string]time.Month{
+		"jan": time.January,
+		"fev": time.February,
+		"mar": time.March,
+		"abr": time.April,
+		"mai": time.May,
+		"jun": time.June,
+		"jul": time.July,
+		"ago": time.August,
+		"set": time.September,
+		"out": time.October,
+		"nov": time.November,
+		"dez": time.December,
+	}
+)
+
+// Parse returns the first date found on str.
+func (p *Parser) Parse(str string) (*time.Time, error) {
+	t, _, err := p.ParseWithPrecision(str)
+	return t, err
+}
+
+// ParseWithPrecision returns the first date found on str along with which
+// parts of it were present on str.
+func (p *Parser) ParseWithPrecision(str string) (*time.Time, Precision, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil, "", errors.New("no date provided")
+	}
+
+	parsers := []func(string) (*time.Time, Precision, error){
+		p.parseNumericDate,
+		p.parseLongDate,
+		p.parseRelativeDay,
+		p.parseAgo,
+	}
+	if englishDateRegexp.MatchString(str) {
+		parsers = nil
+	}
+	for _, parse := range parsers {
+		t, precision, err := parse(str)
+		if err != nil {
+			return nil, "", err
+		}
+		if t != nil {
+			return t, precision, nil
+		}
+	}
+
+	loc := p.location()
+	if strings.HasSuffix(str, " UTC") || strings.HasSuffix(str, " GMT") {
+		loc = time.UTC
+	}
+	t, err := dateparse.ParseIn(str, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	if t.IsZero() {
+		return nil, "", nil
+	}
+	return &t, guessPrecision(str), nil
+}
+
+func (p *Parser) parseNumericDate(str string) (*time.Time, Precision, error) {
+	match := numericDateRegexp.FindStringSubmatch(str)
+	if match == nil {
+		return nil, "", nil
+	}
+
+	// Dates with dots or dashes are on the last submatches
+	if match[2] == "" {
+		match[2], match[3] = match[4], match[5]
+	}
+	day, _ := strconv.Atoi(match[1])
+	month, _ := strconv.Atoi(match[2])
+	year, _ := strconv.Atoi(match[3])
+	if len(match[3]) == 2 {
+		year += 2000
+	}
+	return p.build(year, time.Month(month), day, match[6:])
+}
+
+func (p *Parser) parseLongDate(str string) (*time.Time, Precision, error) {
+	match := longDateRegexp.FindStringSubmatch(str)
+	if match == nil {
+		return nil, "", nil
+	}
+
+	day, _ := strconv.Atoi(match[1])
+	month := months[strings.Replace(strings.ToLower(match[2]), "ç", "c", 1)[0:3]]
+	year, _ := strconv.Atoi(match[3])
+	return p.build(year, month, day, match[4:])
+}
+
+func (p *Parser) parseRelativeDay(str string) (*time.Time, Precision, error) {
+	match := relativeDayRegexp.FindStringSubmatch(str)
+	if match == nil {
+		return nil, "", nil
+	}
+
+	now := p.now()
+	switch strings.ToLower(match[1]) {
+	case "ontem":
+		now = now.AddDate(0, 0, -1)
+	case "anteontem":
+		now = now.AddDate(0, 0, -2)
+	}
+	return p.build(now.Year(), now.Month(), now.Day(), match[2:])
+}
+
+func (p *Parser) parseAgo(str string) (*time.Time, Precision, error) {
+	match := agoRegexp.FindStringSubmatch(str)
+	if match == nil {
+		return nil, "", nil
+	}
+
+	amount, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := p.now()
+	var t time.Time
+	precision := PrecisionDay
+	switch unit := strings.ToLower(match[2]); {
+	case strings.HasPrefix(unit, "min"):
+		t = now.Add(-time.Duration(amount) * time.Minute).Truncate(time.Minute)
+		precision = PrecisionMinute
+	case strings.HasPrefix(unit, "h"):
+		t = now.Add(-time.Duration(amount) * time.Hour).Truncate(time.Minute)
+		precision = PrecisionMinute
+	case strings.HasPrefix(unit, "dia"):
+		t = midnight(now.AddDate(0, 0, -amount))
+	case strings.HasPrefix(unit, "semana"):
+		t = midnight(now.AddDate(0, 0, -7*amount))
+	default:
+		t = midnight(now.AddDate(0, -amount, 0))
+	}
+	return &t, precision, nil
+}
+
+// build creates a time from the date provided and the hour, minute, second
+// and am/pm submatches of timePattern.
+func (p *Parser) build(year int, month time.Month, day int, timeMatch []string) (*time.Time, Precision, error) {
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return nil, "", fmt.Errorf("invalid date %02d/%02d/%d", day, month, year)
+	}
+
+	precision := PrecisionDay
+	if timeMatch[2] != "" {
+		precision = PrecisionSecond
+	} else if timeMatch[0] != "" {
+		precision = PrecisionMinute
+	}
+
+	hour, _ := strconv.Atoi(timeMatch[0])
+	minute, _ := strconv.Atoi(timeMatch[1])
+	second, _ := strconv.Atoi(timeMatch[2])
+	switch strings.ToLower(timeMatch[3]) {
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return nil, "", fmt.Errorf("invalid time %02d:%02d:%02d", hour, minute, second)
+	}
+
+	t := time.Date(year, month, day, hour, minute, second, 0, p.location())
+	if t.Day() != day {
+		return nil, "", fmt.Errorf("invalid date %02d/%02d/%d", day, month, year)
+	}
+	return &t, precision, nil
+}
+
+// guessPrecision tells the precision of dates parsed by dateparse based on
+// the parts found on str.
+func guessPrecision(str string) Precision {
+	switch {
+	case yearOnlyRegexp.MatchString(str):
+		return PrecisionYear
+	case monthOnlyRegexp.MatchString(str):
+		return PrecisionMonth
+	case secondsRegexp.MatchString(str):
+		return PrecisionSecond
+	case minutesRegexp.MatchString(str):
+		return PrecisionMinute
+	default:
+		return PrecisionDay
+	}
+}
+
+func (p *Parser) location() *time.Location {
+	if p.Location == nil {
+		return DefaultLocation
+	}
+	return p.Location
+}
+
+func (p *Parser) now() time.Time {
+	clock := p.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	return clock.Now().In(p.location())
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,13 @@
+package dates_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dates Suite")
+}
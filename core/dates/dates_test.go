@@ -0,0 +1,173 @@
+package dates_test
+
+import (
+	"time"
+
+	. "github.com/fgrehm/brinfo/core/dates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+var _ = Describe("Parser", func() {
+	brLoc := DefaultLocation
+	now := time.Date(2020, 5, 14, 15, 20, 33, 0, brLoc)
+	parser := &Parser{Clock: fakeClock{now}}
+
+	cases := []struct {
+		input    string
+		expected time.Time
+	}{
+		// Numeric dates
+		{"22/02/2020 15:50", time.Date(2020, 2, 22, 15, 50, 0, 0, brLoc)},
+		{"21/02/2020 16h50", time.Date(2020, 2, 21, 16, 50, 0, 0, brLoc)},
+		{"21/02/2020 - 16:50", time.Date(2020, 2, 21, 16, 50, 0, 0, brLoc)},
+		{"21/02/2020 - 16h50", time.Date(2020, 2, 21, 16, 50, 0, 0, brLoc)},
+		{"21/02/2020 - 16:50:11", time.Date(2020, 2, 21, 16, 50, 11, 0, brLoc)},
+		{"21/02/2020 16h50min", time.Date(2020, 2, 21, 16, 50, 0, 0, brLoc)},
+		{"21/02/2020 às 16h", time.Date(2020, 2, 21, 16, 0, 0, 0, brLoc)},
+		{"12/05/2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"12.05.2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"12/05/20", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"12-05-2020 10h30", time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)},
+		{"Publicado em 12/05/2020 10h30", time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)},
+
+		// Weekday prefixes
+		{"Terça-feira, 12/05/2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"terça-feira, 12 de maio de 2020, 10:30", time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)},
+		{"Sáb, 16 mai 2020", time.Date(2020, 5, 16, 0, 0, 0, 0, brLoc)},
+
+		// Long and abbreviated months
+		{"21 de FeVereiro dE 2020 àS 16:26 | Geral", time.Date(2020, 2, 21, 16, 26, 0, 0, brLoc)},
+		{"Publicação: 22 de março dE 2020 àS 16:22 | Geral", time.Date(2020, 3, 22, 16, 22, 0, 0, brLoc)},
+		{"21/fevereiro/2020 4:26 pm | Geral", time.Date(2020, 2, 21, 16, 26, 0, 0, brLoc)},
+		{"12 de maio de 2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"1º de dezembro de 2020", time.Date(2020, 12, 1, 0, 0, 0, 0, brLoc)},
+		{"12 mai 2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"12 MAI. 2020 - 09h15", time.Date(2020, 5, 12, 9, 15, 0, 0, brLoc)},
+		{"12/mai/2020", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"3 de marco de 2020", time.Date(2020, 3, 3, 0, 0, 0, 0, brLoc)},
+		{"10 dez 2019", time.Date(2019, 12, 10, 0, 0, 0, 0, brLoc)},
+
+		// Published / updated pairs
+		{"Publicado em 12/05/2020 10h30 Atualizado em 13/05/2020 09h00", time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)},
+		{"Publicado: 12 de maio de 2020 às 10:30, atualizado em 13 de maio de 2020", time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)},
+
+		// Relative dates
+		{"há 3 horas", time.Date(2020, 5, 14, 12, 20, 0, 0, brLoc)},
+		{"Há 15 minutos", time.Date(2020, 5, 14, 15, 5, 0, 0, brLoc)},
+		{"há 2 dias", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+		{"há 1 semana", time.Date(2020, 5, 7, 0, 0, 0, 0, brLoc)},
+		{"hoje", time.Date(2020, 5, 14, 0, 0, 0, 0, brLoc)},
+		{"Hoje, 10:45", time.Date(2020, 5, 14, 10, 45, 0, 0, brLoc)},
+		{"ontem", time.Date(2020, 5, 13, 0, 0, 0, 0, brLoc)},
+		{"ontem às 18h30", time.Date(2020, 5, 13, 18, 30, 0, 0, brLoc)},
+		{"anteontem", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)},
+
+		// Other formats are handled by dateparse
+		{"2010-02-21 15:50:00", time.Date(2010, 2, 21, 15, 50, 0, 0, brLoc)},
+		{"2020-06-21T15:53:10-03:00", time.Date(2020, 6, 21, 15, 53, 10, 0, brLoc)},
+		{"Mon, 15 Jun 2020 22:56:00 -0700", time.Date(2020, 6, 16, 5, 56, 0, 0, time.UTC)},
+		{"Mon, 15 Jun 2020 22:56:00 +0000", time.Date(2020, 6, 15, 22, 56, 0, 0, time.UTC)},
+		{"Mon, 15 Jun 2020 22:56:00 GMT", time.Date(2020, 6, 15, 22, 56, 0, 0, time.UTC)},
+		{"15 Jun 2020 22:56:00 -0700", time.Date(2020, 6, 16, 5, 56, 0, 0, time.UTC)},
+		{"Mon, 15 Jun 2020 22:56:00", time.Date(2020, 6, 15, 22, 56, 0, 0, brLoc)},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("parses '"+c.input+"'", func() {
+			t, err := parser.Parse(c.input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).NotTo(BeNil())
+			Expect(t.Equal(c.expected)).To(BeTrue(), "expected %s to equal %s", t, c.expected)
+		})
+	}
+
+	invalid := []string{
+		"",
+		"foo",
+		"31/02/2020",
+		"12/13/2020 10:00",
+		"12/05/2020 25h00",
+		"Versão 1.2.20",
+		"12-05-20",
+	}
+
+	for _, input := range invalid {
+		input := input
+		It("errors on '"+input+"'", func() {
+			t, err := parser.Parse(input)
+			Expect(err).To(HaveOccurred())
+			Expect(t).To(BeNil())
+		})
+	}
+
+	It("interprets times in the location provided", func() {
+		manausLoc, err := time.LoadLocation("America/Manaus")
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := (&Parser{Location: manausLoc}).Parse("12/05/2020 10h30")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*t).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, manausLoc)))
+	})
+})
+
+var _ = Describe("Parser.ParseLabeled", func() {
+	brLoc := DefaultLocation
+	parser := &Parser{}
+
+	It("finds published and modified dates", func() {
+		dates := parser.ParseLabeled("Publicado: 12/05/2020 10h30, última modificação: 13/05/2020 09h00")
+		Expect(dates).NotTo(BeNil())
+		Expect(*dates.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+		Expect(*dates.ModifiedAt).To(Equal(time.Date(2020, 5, 13, 9, 0, 0, 0, brLoc)))
+
+		dates = parser.ParseLabeled("Atualizado em 13 de maio de 2020 às 09:00 | Publicado em 12 de maio de 2020 às 10:30")
+		Expect(dates).NotTo(BeNil())
+		Expect(*dates.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+		Expect(*dates.ModifiedAt).To(Equal(time.Date(2020, 5, 13, 9, 0, 0, 0, brLoc)))
+	})
+
+	It("handles text broken into multiple lines", func() {
+		dates := parser.ParseLabeled(`
+			publicado:
+			12/05/2020 10h30,
+			última modificação:
+			13/05/2020 09h00`)
+		Expect(dates).NotTo(BeNil())
+		Expect(*dates.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+		Expect(*dates.ModifiedAt).To(Equal(time.Date(2020, 5, 13, 9, 0, 0, 0, brLoc)))
+	})
+
+	It("returns a single date when only one is labeled", func() {
+		dates := parser.ParseLabeled("Publicado em 12/05/2020")
+		Expect(dates).NotTo(BeNil())
+		Expect(*dates.PublishedAt).To(Equal(time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc)))
+		Expect(dates.ModifiedAt).To(BeNil())
+	})
+
+	It("returns nil when no labeled dates are found", func() {
+		Expect(parser.ParseLabeled("12/05/2020 10h30")).To(BeNil())
+		Expect(parser.ParseLabeled("Publicado por Secom")).To(BeNil())
+	})
+
+	It("ignores labels within the text of articles", func() {
+		Expect(parser.ParseLabeled("A comissão foi criada em 12/03/2019 pelo governador")).To(BeNil())
+		Expect(parser.ParseLabeled("A lei foi alterada em 12/03/2019")).To(BeNil())
+		Expect(parser.ParseLabeled("Os republicados 12/03/2019")).To(BeNil())
+		Expect(parser.ParseLabeled("Desatualizado em 12/03/2019")).To(BeNil())
+
+		dates := parser.ParseLabeled("Criado: 12/05/2020 10h30")
+		Expect(dates).NotTo(BeNil())
+		Expect(*dates.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+	})
+})
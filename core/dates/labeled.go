@@ -0,0 +1,76 @@
+package dates
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	publishedLabelRegexp = regexp.MustCompile(`(?i)^(publicad[oa]|publicação|publicacao|postad[oa]|criad[oa])`)
+	// labelsRegexp matches labels at the start of a word followed by a colon
+	// or "em", the ones that are also common on the body of articles like
+	// "criada" are only matched when followed by a colon.
+	labelsRegexp = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(?:` +
+		`(publicad[oa]|publicação|publicacao|postad[oa]|atualizad[oa]|atualização|atualizacao|última\s+modificação|ultima\s+modificacao|modificad[oa])\s*(?::|em\b:?)|` +
+		`(criad[oa]|alterad[oa]|editad[oa])\s*:)`)
+)
+
+// LabeledDates are the dates found on a text that labels the moment something
+// was published and when it was last modified, like "Publicado: 12/05/2020
+// 10h30, última modificação: 13/05/2020 09h00".
+type LabeledDates struct {
+	PublishedAt          *time.Time
+	PublishedAtPrecision Precision
+	ModifiedAt           *time.Time
+	ModifiedAtPrecision  Precision
+}
+
+// ParseLabeled looks for labeled dates on str, returning nil if none are
+// found. Text following each label is parsed up to the next label, the first
+// date found for each kind of label wins.
+func (p *Parser) ParseLabeled(str string) *LabeledDates {
+	locs := labelsRegexp.FindAllStringSubmatchIndex(str, -1)
+	if len(locs) == 0 {
+		return nil
+	}
+
+	result := &LabeledDates{}
+	for i, loc := range locs {
+		end := len(str)
+		if i+1 < len(locs) {
+			end = labelStart(locs[i+1])
+		}
+		segment := strings.TrimSpace(str[loc[1]:end])
+		if segment == "" {
+			continue
+		}
+
+		t, precision, err := p.ParseWithPrecision(segment)
+		if err != nil || t == nil {
+			continue
+		}
+
+		if publishedLabelRegexp.MatchString(str[labelStart(loc):loc[1]]) {
+			if result.PublishedAt == nil {
+				result.PublishedAt, result.PublishedAtPrecision = t, precision
+			}
+		} else if result.ModifiedAt == nil {
+			result.ModifiedAt, result.ModifiedAtPrecision = t, precision
+		}
+	}
+
+	if result.PublishedAt == nil && result.ModifiedAt == nil {
+		return nil
+	}
+	return result
+}
+
+// labelStart returns where the label matched by labelsRegexp starts, which is
+// on one of its groups.
+func labelStart(loc []int) int {
+	if loc[2] >= 0 {
+		return loc[2]
+	}
+	return loc[4]
+}
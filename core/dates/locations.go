@@ -0,0 +1,97 @@
+package dates
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StateTimezones maps the abbreviation of each Brazilian state to the IANA
+// timezone used by its capital.
+var StateTimezones = map[string]string{
+	"AC": "America/Rio_Branco",
+	"AL": "America/Maceio",
+	"AM": "America/Manaus",
+	"AP": "America/Belem",
+	"BA": "America/Bahia",
+	"CE": "America/Fortaleza",
+	"DF": "America/Sao_Paulo",
+	"ES": "America/Sao_Paulo",
+	"GO": "America/Sao_Paulo",
+	"MA": "America/Fortaleza",
+	"MG": "America/Sao_Paulo",
+	"MS": "America/Campo_Grande",
+	"MT": "America/Cuiaba",
+	"PA": "America/Belem",
+	"PB": "America/Fortaleza",
+	"PE": "America/Recife",
+	"PI": "America/Fortaleza",
+	"PR": "America/Sao_Paulo",
+	"RJ": "America/Sao_Paulo",
+	"RN": "America/Fortaleza",
+	"RO": "America/Porto_Velho",
+	"RR": "America/Boa_Vista",
+	"RS": "America/Sao_Paulo",
+	"SC": "America/Sao_Paulo",
+	"SE": "America/Maceio",
+	"SP": "America/Sao_Paulo",
+	"TO": "America/Araguaina",
+}
+
+var (
+	stateHostRegexp   = regexp.MustCompile(`(?:^|\.)([a-z]{2})\.(?:gov|leg|jus|mp)\.br$`)
+	noronhaHostRegexp = regexp.MustCompile(`(?:^|\.)noronha\.pe\.gov\.br$`)
+)
+
+// LoadLocation loads a location from either a state abbreviation (like "AM")
+// or an IANA timezone name (like "America/Manaus").
+func LoadLocation(name string) (*time.Location, error) {
+	if tz, ok := StateTimezones[strings.ToUpper(name)]; ok {
+		name = tz
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone '%s'", name)
+	}
+	return loc, nil
+}
+
+// StateFromURL returns the abbreviation of the state a government website
+// belongs to based on its host (like www.amazonas.am.gov.br). An empty string
+// is returned for federal websites and for hosts that don't follow the
+// convention.
+func StateFromURL(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	match := stateHostRegexp.FindStringSubmatch(strings.ToLower(u.Hostname()))
+	if match == nil {
+		return ""
+	}
+	state := strings.ToUpper(match[1])
+	if _, ok := StateTimezones[state]; !ok {
+		return ""
+	}
+	return state
+}
+
+// LocationForURL returns the location times published on pageURL are likely
+// to be in, falling back to DefaultLocation when it can't be determined.
+func LocationForURL(pageURL string) *time.Location {
+	name := StateFromURL(pageURL)
+	if u, err := url.Parse(pageURL); err == nil && noronhaHostRegexp.MatchString(strings.ToLower(u.Hostname())) {
+		name = "America/Noronha"
+	}
+	if name == "" {
+		return DefaultLocation
+	}
+
+	loc, err := LoadLocation(name)
+	if err != nil {
+		return DefaultLocation
+	}
+	return loc
+}
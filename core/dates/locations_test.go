@@ -0,0 +1,56 @@
+package dates_test
+
+import (
+	"time"
+
+	. "github.com/fgrehm/brinfo/core/dates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locations", func() {
+	It("loads locations from states and timezone names", func() {
+		loc, err := LoadLocation("am")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loc.String()).To(Equal("America/Manaus"))
+
+		loc, err = LoadLocation("America/Rio_Branco")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loc.String()).To(Equal("America/Rio_Branco"))
+
+		_, err = LoadLocation("XX")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("derives the state from government hosts", func() {
+		Expect(StateFromURL("http://www.amazonas.am.gov.br/2020/05/noticia/")).To(Equal("AM"))
+		Expect(StateFromURL("https://agencia.ac.gov.br/noticia")).To(Equal("AC"))
+		Expect(StateFromURL("https://www.al.rs.leg.br/noticia")).To(Equal("RS"))
+		Expect(StateFromURL("https://www.saude.gov.br/noticias")).To(Equal(""))
+		Expect(StateFromURL("https://www.xx.gov.br/noticias")).To(Equal(""))
+		Expect(StateFromURL("https://example.com")).To(Equal(""))
+	})
+
+	It("derives the location from the URL", func() {
+		Expect(LocationForURL("https://agencia.ac.gov.br/noticia").String()).To(Equal("America/Rio_Branco"))
+		Expect(LocationForURL("https://www.noronha.pe.gov.br/noticia").String()).To(Equal("America/Noronha"))
+		Expect(LocationForURL("https://www.pe.gov.br/noticia").String()).To(Equal("America/Recife"))
+		Expect(LocationForURL("https://www.saude.gov.br/noticia")).To(Equal(DefaultLocation))
+	})
+
+	It("interprets times without offset in the location of the parser", func() {
+		loc, err := LoadLocation("AC")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser := &Parser{Location: loc}
+		t, err := parser.Parse("21/02/2020 16h50")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*t).To(Equal(time.Date(2020, 2, 21, 16, 50, 0, 0, loc)))
+		Expect(t.UTC().Hour()).To(Equal(21))
+
+		t, err = parser.Parse("2020-02-21T16:50:00-03:00")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.UTC().Hour()).To(Equal(19))
+	})
+})
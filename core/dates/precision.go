@@ -0,0 +1,59 @@
+package dates
+
+import "time"
+
+// Precision tells which parts of a date are known, a date with PrecisionDay
+// is set to midnight since the time of the day is unknown.
+type Precision string
+
+const (
+	PrecisionYear   Precision = "year"
+	PrecisionMonth  Precision = "month"
+	PrecisionDay    Precision = "day"
+	PrecisionMinute Precision = "minute"
+	PrecisionSecond Precision = "second"
+)
+
+var precisionRanks = map[Precision]int{
+	PrecisionYear:   1,
+	PrecisionMonth:  2,
+	PrecisionDay:    3,
+	PrecisionMinute: 4,
+	PrecisionSecond: 5,
+}
+
+// MorePreciseThan returns true if both precisions are known and p is more
+// precise than other.
+func (p Precision) MorePreciseThan(other Precision) bool {
+	rank, otherRank := precisionRanks[p], precisionRanks[other]
+	return rank > 0 && otherRank > 0 && rank > otherRank
+}
+
+// PrecisionOf guesses the precision of a time that was not parsed by Parser
+// based on the parts of it that are set.
+func PrecisionOf(t time.Time) Precision {
+	switch {
+	case t.Second() != 0 || t.Nanosecond() != 0:
+		return PrecisionSecond
+	case t.Hour() != 0 || t.Minute() != 0:
+		return PrecisionMinute
+	default:
+		return PrecisionDay
+	}
+}
+
+// Truncate drops the parts of t that are not known according to precision.
+func Truncate(t time.Time, precision Precision) time.Time {
+	switch precision {
+	case PrecisionYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	case PrecisionMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case PrecisionDay:
+		return midnight(t)
+	case PrecisionMinute:
+		return t.Truncate(time.Minute)
+	default:
+		return t
+	}
+}
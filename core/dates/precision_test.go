@@ -0,0 +1,62 @@
+package dates_test
+
+import (
+	"time"
+
+	. "github.com/fgrehm/brinfo/core/dates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Precision", func() {
+	now := time.Date(2020, 5, 14, 15, 20, 33, 0, DefaultLocation)
+	parser := &Parser{Clock: fakeClock{now}}
+
+	cases := []struct {
+		input     string
+		precision Precision
+	}{
+		{"12/05/2020", PrecisionDay},
+		{"12/05/2020 10h30", PrecisionMinute},
+		{"21/02/2020 às 16h", PrecisionMinute},
+		{"21/02/2020 - 16:50:11", PrecisionSecond},
+		{"12 de maio de 2020", PrecisionDay},
+		{"12 de maio de 2020 às 10:30", PrecisionMinute},
+		{"ontem", PrecisionDay},
+		{"hoje às 10h", PrecisionMinute},
+		{"há 3 horas", PrecisionMinute},
+		{"há 2 dias", PrecisionDay},
+		{"2020-05-12", PrecisionDay},
+		{"2020-05-12T10:30", PrecisionMinute},
+		{"2020-05-12T10:30:15-03:00", PrecisionSecond},
+		{"2020-05", PrecisionMonth},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("tells the precision of "+c.input, func() {
+			t, precision, err := parser.ParseWithPrecision(c.input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).NotTo(BeNil())
+			Expect(precision).To(Equal(c.precision))
+		})
+	}
+
+	It("compares precisions", func() {
+		Expect(PrecisionMinute.MorePreciseThan(PrecisionDay)).To(BeTrue())
+		Expect(PrecisionDay.MorePreciseThan(PrecisionMinute)).To(BeFalse())
+		Expect(PrecisionDay.MorePreciseThan(PrecisionDay)).To(BeFalse())
+		Expect(PrecisionMinute.MorePreciseThan("")).To(BeFalse())
+		Expect(Precision("").MorePreciseThan(PrecisionYear)).To(BeFalse())
+	})
+
+	It("truncates times to a precision", func() {
+		t := time.Date(2020, 5, 14, 15, 20, 33, 0, DefaultLocation)
+		Expect(Truncate(t, PrecisionYear)).To(Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, DefaultLocation)))
+		Expect(Truncate(t, PrecisionMonth)).To(Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, DefaultLocation)))
+		Expect(Truncate(t, PrecisionDay)).To(Equal(time.Date(2020, 5, 14, 0, 0, 0, 0, DefaultLocation)))
+		Expect(Truncate(t, PrecisionMinute)).To(Equal(time.Date(2020, 5, 14, 15, 20, 0, 0, DefaultLocation)))
+		Expect(Truncate(t, PrecisionSecond)).To(Equal(t))
+	})
+})
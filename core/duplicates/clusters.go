@@ -0,0 +1,117 @@
+package duplicates
+
+import (
+	"sort"
+
+	"github.com/fgrehm/brinfo/core"
+)
+
+// Cluster is a group of articles with the same content, Original is the
+// article that was published first.
+type Cluster struct {
+	Original   *core.ArticleData
+	Duplicates []*Duplicate
+}
+
+// Duplicate is an article found to be a copy of the original of its cluster,
+// Distance is how many bits their fingerprints differ on.
+type Duplicate struct {
+	Article  *core.ArticleData
+	Distance int
+}
+
+// FindClusters groups the articles that have fingerprints at most maxDistance
+// bits apart from the original of the group, which is the article published
+// first. Articles are compared with the originals in the order they were
+// published and join the closest one, so that a chain of small edits doesn't
+// pull unrelated articles into the same group. Articles that don't have a
+// fingerprint get one computed from their full text and the ones without text
+// are ignored. Only groups with duplicates are returned, in the order their
+// first article appears on articles.
+func FindClusters(articles []*core.ArticleData, maxDistance int) []*Cluster {
+	candidates := []*core.ArticleData{}
+	fingerprints := []uint64{}
+	for _, a := range articles {
+		fingerprint := a.Fingerprint
+		if fingerprint == "" {
+			fingerprint = Fingerprint(a.FullText)
+		}
+		parsed, err := parse(fingerprint)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, a)
+		fingerprints = append(fingerprints, parsed)
+	}
+
+	byPublication := make([]int, len(candidates))
+	for i := range byPublication {
+		byPublication[i] = i
+	}
+	sort.SliceStable(byPublication, func(a, b int) bool {
+		return publishedBefore(candidates[byPublication[a]], candidates[byPublication[b]])
+	})
+
+	originals := []int{}
+	originalOf := make([]int, len(candidates))
+	for _, i := range byPublication {
+		originalOf[i] = i
+		closest := maxDistance + 1
+		for _, o := range originals {
+			if d := distance(fingerprints[i], fingerprints[o]); d < closest {
+				originalOf[i], closest = o, d
+			}
+		}
+		if originalOf[i] == i {
+			originals = append(originals, i)
+		}
+	}
+
+	members := map[int][]int{}
+	roots := []int{}
+	for i := range candidates {
+		root := originalOf[i]
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	clusters := []*Cluster{}
+	for _, original := range roots {
+		indexes := members[original]
+		if len(indexes) < 2 {
+			continue
+		}
+
+		cluster := &Cluster{Original: candidates[original], Duplicates: []*Duplicate{}}
+		for _, i := range indexes {
+			if i == original {
+				continue
+			}
+			cluster.Duplicates = append(cluster.Duplicates, &Duplicate{
+				Article:  candidates[i],
+				Distance: distance(fingerprints[i], fingerprints[original]),
+			})
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// publishedBefore tells if a was published before b, articles without a
+// publication date are considered to be published after the ones that have
+// it and are compared by the time they were found.
+func publishedBefore(a, b *core.ArticleData) bool {
+	switch {
+	case a.PublishedAt != nil && b.PublishedAt != nil:
+		if !a.PublishedAt.Equal(*b.PublishedAt) {
+			return a.PublishedAt.Before(*b.PublishedAt)
+		}
+	case a.PublishedAt != nil:
+		return true
+	case b.PublishedAt != nil:
+		return false
+	}
+	return !a.FoundAt.IsZero() && a.FoundAt.Before(b.FoundAt)
+}
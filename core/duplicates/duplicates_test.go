@@ -0,0 +1,110 @@
+package duplicates_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/duplicates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const agenciaBrasilNote = `O Ministério da Saúde divulgou nesta terça-feira um novo boletim epidemiológico com dados atualizados sobre a pandemia no país. Segundo o documento, os estados das regiões Norte e Nordeste concentram a maior parte dos novos casos confirmados na última semana, enquanto o Sul registrou queda no número de internações. A pasta reforça a importância das medidas de distanciamento social, do uso de máscaras e da higienização frequente das mãos. O boletim também traz informações sobre a ocupação de leitos de unidades de terapia intensiva, que segue acima de 80% em cinco capitais. De acordo com o ministério, a distribuição de vacinas para os estados será ampliada a partir da próxima semana, com prioridade para profissionais de saúde e idosos.`
+
+const otherNote = `A Secretaria da Educação abriu as inscrições para o processo seletivo de professores temporários da rede estadual. Os candidatos devem se inscrever pela internet até o dia 30 e apresentar os documentos exigidos no edital. As vagas são para todas as regiões do estado e a classificação levará em conta a formação e o tempo de experiência em sala de aula.`
+
+// republish mimics the edits sites make when copying the note.
+func republish(text string) string {
+	text = strings.Replace(text, "nesta terça-feira", "na terça-feira (12)", 1)
+	return "Da Agência Brasil. " + text + " Leia também: outras notícias."
+}
+
+var _ = Describe("Fingerprint", func() {
+	It("is close for texts with minor edits", func() {
+		distance, err := Distance(Fingerprint(agenciaBrasilNote), Fingerprint(republish(agenciaBrasilNote)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(distance).To(BeNumerically("<=", DefaultMaxDistance))
+	})
+
+	It("ignores case and punctuation", func() {
+		Expect(Fingerprint(strings.ToUpper(agenciaBrasilNote))).To(Equal(Fingerprint(agenciaBrasilNote)))
+		Expect(Fingerprint("Boletim, epidemiológico: dados!")).To(Equal(Fingerprint("boletim epidemiológico dados")))
+	})
+
+	It("is far for different texts", func() {
+		distance, err := Distance(Fingerprint(agenciaBrasilNote), Fingerprint(otherNote))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(distance).To(BeNumerically(">", 10))
+	})
+
+	It("is empty for texts without words", func() {
+		Expect(Fingerprint("")).To(BeEmpty())
+		Expect(Fingerprint(" - ")).To(BeEmpty())
+	})
+
+	It("has 16 hexadecimal digits", func() {
+		Expect(Fingerprint("boletim")).To(MatchRegexp(`^[0-9a-f]{16}$`))
+	})
+})
+
+var _ = Describe("Distance", func() {
+	It("counts the bits that differ", func() {
+		Expect(Distance("0000000000000000", "000000000000000f")).To(Equal(4))
+		Expect(Distance("ffffffffffffffff", "ffffffffffffffff")).To(Equal(0))
+	})
+
+	It("fails for invalid fingerprints", func() {
+		_, err := Distance("not a fingerprint", "0000000000000000")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("FindClusters", func() {
+	at := func(day int) *time.Time {
+		t := time.Date(2020, 5, day, 10, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	It("groups republished articles, taking the earliest as the original", func() {
+		state := &core.ArticleData{URL: "https://www.es.gov.br/boletim", FullText: republish(agenciaBrasilNote), PublishedAt: at(13)}
+		agencia := &core.ArticleData{URL: "https://agenciabrasil.ebc.com.br/boletim", FullText: agenciaBrasilNote, PublishedAt: at(12)}
+		other := &core.ArticleData{URL: "https://www.es.gov.br/professores", FullText: otherNote, PublishedAt: at(10)}
+		city := &core.ArticleData{URL: "https://www.vitoria.es.gov.br/boletim", FullText: republish(agenciaBrasilNote)}
+		empty := &core.ArticleData{URL: "https://www.es.gov.br/galeria"}
+
+		clusters := FindClusters([]*core.ArticleData{state, agencia, other, city, empty}, DefaultMaxDistance)
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Original).To(Equal(agencia))
+		Expect(clusters[0].Duplicates).To(HaveLen(2))
+		Expect(clusters[0].Duplicates[0].Article).To(Equal(state))
+		Expect(clusters[0].Duplicates[0].Distance).To(BeNumerically("<=", DefaultMaxDistance))
+		Expect(clusters[0].Duplicates[1].Article).To(Equal(city))
+	})
+
+	It("uses the fingerprints that are set", func() {
+		a := &core.ArticleData{URL: "a", Fingerprint: "0000000000000000"}
+		b := &core.ArticleData{URL: "b", Fingerprint: "0000000000000003"}
+		c := &core.ArticleData{URL: "c", Fingerprint: "00000000000000ff"}
+
+		clusters := FindClusters([]*core.ArticleData{a, b, c}, 2)
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Original).To(Equal(a))
+		Expect(clusters[0].Duplicates).To(Equal([]*Duplicate{{Article: b, Distance: 2}}))
+	})
+
+	It("compares articles with the original of the cluster instead of chaining them", func() {
+		a := &core.ArticleData{URL: "a", Fingerprint: "0000000000000000", PublishedAt: at(10)}
+		b := &core.ArticleData{URL: "b", Fingerprint: "0000000000000003", PublishedAt: at(11)}
+		c := &core.ArticleData{URL: "c", Fingerprint: "000000000000000f", PublishedAt: at(12)}
+		d := &core.ArticleData{URL: "d", Fingerprint: "000000000000003f", PublishedAt: at(13)}
+
+		clusters := FindClusters([]*core.ArticleData{d, c, b, a}, 2)
+		Expect(clusters).To(HaveLen(2))
+		Expect(clusters[0].Original).To(Equal(c))
+		Expect(clusters[0].Duplicates).To(Equal([]*Duplicate{{Article: d, Distance: 2}}))
+		Expect(clusters[1].Original).To(Equal(a))
+		Expect(clusters[1].Duplicates).To(Equal([]*Duplicate{{Article: b, Distance: 2}}))
+	})
+})
@@ -0,0 +1,104 @@
+// Package duplicates finds articles that are the same note republished with
+// minor edits, like the ones from Agência Brasil that state and municipal
+// websites copy, using SimHash fingerprints of their full text.
+package duplicates
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxDistance is the number of bits two fingerprints may differ on for
+// their articles to be considered duplicates. Fingerprints of unrelated texts
+// differ on about half of their bits, while copies with a credit line and a
+// changed sentence added by the site republishing them usually differ on less
+// than 8.
+const DefaultMaxDistance = 8
+
+// shingleSize is the number of consecutive words hashed together, so that the
+// fingerprint depends on the order of words and not only on the vocabulary.
+const shingleSize = 3
+
+// Fingerprint returns the 64 bit SimHash of text as a hexadecimal string.
+// Texts that differ on a few words have fingerprints that differ on a few
+// bits. An empty string is returned for texts without words.
+func Fingerprint(text string) string {
+	words := words(text)
+	if len(words) == 0 {
+		return ""
+	}
+	return format(simhash(shingles(words)))
+}
+
+// Distance returns the number of bits fingerprints a and b differ on.
+func Distance(a, b string) (int, error) {
+	x, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return distance(x, y), nil
+}
+
+func distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// words returns the lowercase words of text, ignoring punctuation.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func shingles(words []string) []string {
+	if len(words) <= shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	result := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		result = append(result, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return result
+}
+
+func simhash(features []string) uint64 {
+	var weights [64]int
+	for _, f := range features {
+		h := fnv.New64a()
+		if _, err := h.Write([]byte(f)); err != nil {
+			panic(err)
+		}
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+func format(fingerprint uint64) string {
+	s := strconv.FormatUint(fingerprint, 16)
+	return strings.Repeat("0", 16-len(s)) + s
+}
+
+func parse(fingerprint string) (uint64, error) {
+	return strconv.ParseUint(fingerprint, 16, 64)
+}
@@ -0,0 +1,192 @@
+package fixtures
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	"github.com/fgrehm/brinfo/core/scrapers"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
+)
+
+// Fixture is a page stored along with the ArticleData that was extracted from
+// it when it was recorded. Each fixture is kept on disk as a pair of files,
+// NAME.html and NAME.json.
+type Fixture struct {
+	Name             string            `json:"-"`
+	HTML             []byte            `json:"-"`
+	URL              string            `json:"url"`
+	HTTPContentType  string            `json:"http_content_type,omitempty"`
+	CustomExtractors string            `json:"custom_extractors,omitempty"`
+	Timezone         string            `json:"timezone,omitempty"`
+	ContentEngine    string            `json:"content_engine,omitempty"`
+	URLRules         []urls.Rule       `json:"url_rules,omitempty"`
+	HashAlgorithm    string            `json:"hash_algorithm,omitempty"`
+	RecordedAt       time.Time         `json:"recorded_at"`
+	Expected         *core.ArticleData `json:"expected"`
+}
+
+// Result holds the differences found when verifying a fixture.
+type Result struct {
+	Fixture *Fixture
+	Changes []*core.FieldChange
+}
+
+func (r *Result) Passed() bool {
+	return len(r.Changes) == 0
+}
+
+// NameFromURL generates a fixture name based on the host of the URL and its
+// hash.
+func NameFromURL(pageURL string) string {
+	host := "page"
+	if u, err := url.Parse(pageURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	sum := sha1.Sum([]byte(pageURL))
+	return host + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// Record extracts data from the HTML provided and sets it as the expected
+// result of the fixture.
+func (f *Fixture) Record(ctx context.Context) error {
+	data, err := f.extract(ctx)
+	if err != nil {
+		return err
+	}
+	data.Extra = nil
+	f.Expected = data
+	return nil
+}
+
+// Verify extracts data from the fixture HTML and compares it with the
+// expected result.
+func (f *Fixture) Verify(ctx context.Context) (*Result, error) {
+	data, err := f.extract(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", f.Name, err)
+	}
+	return &Result{Fixture: f, Changes: f.Expected.Diff(data)}, nil
+}
+
+func (f *Fixture) extract(ctx context.Context) (*core.ArticleData, error) {
+	basicArticle, err := xt.BasicArticleWithContentEngine(f.ContentEngine)
+	if err != nil {
+		return nil, err
+	}
+	extractors := []xt.Extractor{basicArticle}
+	if f.CustomExtractors != "" {
+		customExtractors, err := xt.FromJSON([]byte(f.CustomExtractors))
+		if err != nil {
+			return nil, err
+		}
+		extractors = append(extractors, customExtractors...)
+	}
+
+	var location *time.Location
+	if f.Timezone != "" {
+		var err error
+		location, err = dates.LoadLocation(f.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scraper := scrapers.NewArticleScraper(&scrapers.ArticleScraperConfig{
+		Clock:         scrapers.FixedClock(f.RecordedAt),
+		Extractors:    extractors,
+		Location:      location,
+		URLRules:      f.URLRules,
+		HashAlgorithm: f.HashAlgorithm,
+	})
+	return scraper.Run(ctx, f.HTML, f.URL, f.HTTPContentType)
+}
+
+// Save writes the fixture files to dir.
+func Save(dir string, f *Fixture) error {
+	if f.Name == "" {
+		f.Name = NameFromURL(f.URL)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	metadata, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, f.Name+".html"), f.HTML, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, f.Name+".json"), append(metadata, '\n'), 0644)
+}
+
+// Load reads all fixtures kept on dir, sorted by name.
+func Load(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := []*Fixture{}
+	for _, path := range paths {
+		f, err := loadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// VerifyAll verifies every fixture kept on dir.
+func VerifyAll(ctx context.Context, dir string) ([]*Result, error) {
+	fixtures, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*Result{}
+	for _, f := range fixtures {
+		result, err := f.Verify(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func loadFixture(path string) (*Fixture, error) {
+	metadata, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fixture{Name: strings.TrimSuffix(filepath.Base(path), ".json")}
+	if err := json.Unmarshal(metadata, f); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if f.Expected == nil {
+		return nil, fmt.Errorf("%s: no expected data recorded", path)
+	}
+
+	f.HTML, err = ioutil.ReadFile(strings.TrimSuffix(path, ".json") + ".html")
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
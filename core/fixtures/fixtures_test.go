@@ -0,0 +1,113 @@
+package fixtures_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/fixtures"
+	"github.com/fgrehm/brinfo/core/hashes"
+	"github.com/fgrehm/brinfo/core/urls"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fixtures", func() {
+	var (
+		ctx context.Context
+		dir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		dir, err = ioutil.TempDir("", "brinfo-fixtures")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	record := func(html, customExtractors string) *Fixture {
+		f := &Fixture{
+			HTML:             []byte(html),
+			URL:              "https://example.com/article",
+			CustomExtractors: customExtractors,
+			RecordedAt:       time.Now(),
+		}
+		Expect(f.Record(ctx)).To(Succeed())
+		Expect(Save(dir, f)).To(Succeed())
+		return f
+	}
+
+	It("saves and loads fixtures", func() {
+		f := record(`<html><head><title>Article title</title></head><body><p>Body</p></body></html>`, "")
+		Expect(f.Name).To(Equal(NameFromURL("https://example.com/article")))
+		Expect(f.Expected.Title).To(Equal("Article title"))
+		Expect(f.Expected.Extra).To(BeNil())
+
+		list, err := Load(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].Name).To(Equal(f.Name))
+		Expect(list[0].HTML).To(Equal(f.HTML))
+		Expect(list[0].Expected.Title).To(Equal("Article title"))
+	})
+
+	It("passes when extractors produce the recorded data", func() {
+		record(`<html><head><title>Article title</title></head><body><p>Body</p><span>22/02/2020 15:50</span></body></html>`, `{"published_at": "span | text::time"}`)
+
+		results, err := VerifyAll(ctx, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Passed()).To(BeTrue())
+	})
+
+	It("keeps the URL rules and the hash algorithm of the source", func() {
+		f := &Fixture{
+			HTML:          []byte(`<html><head><title>Article title</title></head><body><p>Body</p></body></html>`),
+			URL:           "https://example.com/noticia.php?id=1&origem=home",
+			URLRules:      []urls.Rule{{KeepParams: []string{"id"}}},
+			HashAlgorithm: hashes.AlgorithmSHA256,
+			RecordedAt:    time.Now(),
+		}
+		Expect(f.Record(ctx)).To(Succeed())
+		Expect(f.Expected.CanonicalURL).To(Equal("https://example.com/noticia.php?id=1"))
+		Expect(f.Expected.URLHash).To(HaveLen(64))
+		Expect(Save(dir, f)).To(Succeed())
+
+		results, err := VerifyAll(ctx, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Fixture.URLRules).To(Equal(f.URLRules))
+		Expect(results[0].Fixture.HashAlgorithm).To(Equal(hashes.AlgorithmSHA256))
+		Expect(results[0].Passed()).To(BeTrue())
+	})
+
+	It("reports field level differences", func() {
+		f := record(`<html><head><title>Article title</title></head><body><p>Body</p></body></html>`, "")
+		f.Expected.Title = "Old title"
+		Expect(Save(dir, f)).To(Succeed())
+
+		results, err := VerifyAll(ctx, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Passed()).To(BeFalse())
+		Expect(results[0].Changes).To(Equal([]*FieldChange{
+			{Field: "title", Before: "Old title", After: "Article title"},
+		}))
+	})
+
+	It("errors when the HTML is missing", func() {
+		f := record(`<html><head><title>Article title</title></head></html>`, "")
+		Expect(os.Remove(filepath.Join(dir, f.Name+".html"))).To(Succeed())
+
+		_, err := Load(dir)
+		Expect(err).To(HaveOccurred())
+	})
+})
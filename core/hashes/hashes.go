@@ -0,0 +1,75 @@
+// Package hashes computes the hashes that identify articles, like the ones
+// used for naming the files they are archived to.
+package hashes
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"strings"
+)
+
+// Algorithms that can be used for hashing, AlgorithmSHA1 is the default for
+// keeping the hashes of articles scraped before stable.
+const (
+	AlgorithmSHA1   = "sha1"
+	AlgorithmSHA256 = "sha256"
+)
+
+var (
+	whitespaceRegexp = regexp.MustCompile(`[ \t\f\v\x{00a0}\x{2007}\x{202f}]+`)
+	// boilerplateLineRegexp matches lines left by sharing and accessibility
+	// widgets, which come and go as sites change their templates
+	boilerplateLineRegexp = regexp.MustCompile(`(?i)^((compartilh[a-z]*|imprimir|enviar por e-?mail|copiar (o )?link|tweet|facebook|twitter|whatsapp|linkedin|telegram|a\+|a-|[:|•])\s*)+$`)
+	replacer              = strings.NewReplacer(
+		"\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u00ad", "",
+		"\u201c", `"`, "\u201d", `"`, "\u2018", "'", "\u2019", "'",
+		"\u2013", "-", "\u2014", "-",
+		"\r\n", "\n", "\r", "\n",
+	)
+)
+
+// New returns the hash function for algorithm, which defaults to
+// AlgorithmSHA1 when empty.
+func New(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "", AlgorithmSHA1:
+		return sha1.New(), nil
+	case AlgorithmSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown hash algorithm '%s'", algorithm)
+	}
+}
+
+// Hash returns the hexadecimal hash of text.
+func Hash(algorithm, text string) (string, error) {
+	h, err := New(algorithm)
+	if err != nil {
+		return "", err
+	}
+	if _, err := h.Write([]byte(text)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NormalizeText removes what changes between extractions of the same text
+// without changing its content: invisible characters, typographic quotes and
+// dashes, repeated whitespace, empty lines and lines left by sharing widgets.
+func NormalizeText(text string) string {
+	text = replacer.Replace(text)
+
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(whitespaceRegexp.ReplaceAllString(line, " "))
+		if line == "" || boilerplateLineRegexp.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+package hashes_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHashes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hashes Suite")
+}
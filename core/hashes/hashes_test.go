@@ -0,0 +1,46 @@
+package hashes_test
+
+import (
+	. "github.com/fgrehm/brinfo/core/hashes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hash", func() {
+	It("uses SHA-1 by default", func() {
+		Expect(Hash("", "brinfo")).To(Equal("a628cf4cca28291b2f3140283383417f469a1b8f"))
+		Expect(Hash(AlgorithmSHA1, "brinfo")).To(Equal("a628cf4cca28291b2f3140283383417f469a1b8f"))
+	})
+
+	It("supports SHA-256", func() {
+		Expect(Hash(AlgorithmSHA256, "brinfo")).To(Equal("1b2c62645ff0df426d534d4efebd3b645d339cddc4e61ab5a10ea002662b0a09"))
+	})
+
+	It("fails for unknown algorithms", func() {
+		_, err := Hash("md5", "brinfo")
+		Expect(err).To(MatchError("unknown hash algorithm 'md5'"))
+	})
+})
+
+var _ = Describe("NormalizeText", func() {
+	cases := []struct {
+		text       string
+		normalized string
+	}{
+		{"Governo anuncia medidas", "Governo anuncia medidas"},
+		{"  Governo \t anuncia medidas  ", "Governo anuncia medidas"},
+		{"Primeira linha\r\n\r\n\nSegunda linha", "Primeira linha\nSegunda linha"},
+		{"O “governo” anunciou – ontem – as ‘medidas’", `O "governo" anunciou - ontem - as 'medidas'`},
+		{"Gover\u200bno anun\u00adcia\u00a0medidas", "Governo anuncia medidas"},
+		{"Compartilhe:\nFacebook | Twitter | WhatsApp\nGoverno anuncia medidas\nImprimir\nA+ A-", "Governo anuncia medidas"},
+		{"Compartilhe esta notícia com seus amigos", "Compartilhe esta notícia com seus amigos"},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("normalizes "+c.text, func() {
+			Expect(NormalizeText(c.text)).To(Equal(c.normalized))
+		})
+	}
+})
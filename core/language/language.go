@@ -0,0 +1,91 @@
+// Package language identifies the language of articles without external
+// services, by counting the function words of each language supported on
+// their text. Function words are frequent on any text and, for the
+// languages supported, mostly exclusive to one of them.
+package language
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Languages identified, as ISO 639-1 codes. Undetermined is used for texts
+// too short to tell and for texts in other languages, like the indigenous
+// ones some municipalities publish in.
+const (
+	Portuguese   = "pt"
+	Spanish      = "es"
+	English      = "en"
+	Undetermined = "und"
+)
+
+const (
+	// minWords is the number of words needed for detecting the language.
+	minWords = 8
+	// minCoverage is the share of words that must be function words of the
+	// language detected, texts in Portuguese usually have more than 25%.
+	minCoverage = 0.1
+)
+
+// profiles are the function words of each language. Words used by more than
+// one of the languages, like "de", "que", "para" and "como" in Portuguese and
+// Spanish, "as" in Portuguese and English and the conjunctions "e" and "o",
+// are left out so that each word counts for a single language.
+var profiles = map[string]map[string]bool{
+	Portuguese: set(`os não são também é à às ao aos do da dos das na nas nos em um uma uns umas
+		nesta neste nessa nesse desta deste dessa desse
+		pelo pela pelos pelas com foi está estão estava seu sua seus suas ou quando será
+		já isso após até então você eles elas ainda onde sem mesmo pode podem têm tem`),
+	Spanish: set(`el los las la del y en un una con por pero muy fue son también su sus le
+		lo al más ha han hay ya cuando donde sin hasta ese esa eso están estaba pueden
+		tiene tienen ellos ellas usted aún`),
+	English: set(`the and of to in is was for on that with by are be this from at it an
+		have has will not their which but were been also its they who would`),
+}
+
+func set(words string) map[string]bool {
+	result := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		result[w] = true
+	}
+	return result
+}
+
+// Detect returns the language text is written in and the confidence on it,
+// which is the share of the function words found on text that belong to the
+// language, 1 when all of them do.
+func Detect(text string) (string, float64) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) < minWords {
+		return Undetermined, 0
+	}
+
+	hits := map[string]int{}
+	total := 0
+	for _, w := range words {
+		for lang, profile := range profiles {
+			if profile[w] {
+				hits[lang]++
+				total++
+			}
+		}
+	}
+
+	langs := []string{}
+	for lang := range hits {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if hits[langs[i]] != hits[langs[j]] {
+			return hits[langs[i]] > hits[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	if len(langs) == 0 || float64(hits[langs[0]])/float64(len(words)) < minCoverage {
+		return Undetermined, 0
+	}
+	return langs[0], float64(hits[langs[0]]) / float64(total)
+}
@@ -0,0 +1,44 @@
+package language_test
+
+import (
+	. "github.com/fgrehm/brinfo/core/language"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detect", func() {
+	texts := map[string]string{
+		Portuguese: `A Prefeitura de Ponta Porã abriu nesta segunda-feira as inscrições para o programa de qualificação profissional. As aulas serão gratuitas e os interessados devem procurar a Secretaria de Assistência Social com os documentos pessoais.`,
+		Spanish:    `La Municipalidad de Pedro Juan Caballero abrió este lunes las inscripciones para el programa de capacitación profesional. Las clases serán gratuitas y los interesados deben acudir a la Secretaría de Acción Social con sus documentos personales.`,
+		English:    `The city of Ponta Porã opened registrations on Monday for the professional training program. The classes are free and those interested should go to the Social Assistance department with their personal documents.`,
+	}
+	for lang, text := range texts {
+		lang, text := lang, text
+		It("detects "+lang, func() {
+			detected, confidence := Detect(text)
+			Expect(detected).To(Equal(lang))
+			Expect(confidence).To(BeNumerically(">", 0.7))
+		})
+	}
+
+	It("uses the share of the function words found that belong to the language as the confidence", func() {
+		// "nesta", "os", "do", "foi" and "pela" are Portuguese, "for" and
+		// "the" are English
+		lang, confidence := Detect(`A Secretaria de Saúde anunciou nesta semana os resultados do programa, que foi chamado de Health for the People pela equipe.`)
+		Expect(lang).To(Equal(Portuguese))
+		Expect(confidence).To(BeNumerically("~", 5.0/7.0, 0.001))
+	})
+
+	It("doesn't determine the language of short texts", func() {
+		lang, confidence := Detect("Inscrições abertas")
+		Expect(lang).To(Equal(Undetermined))
+		Expect(confidence).To(BeZero())
+	})
+
+	It("doesn't determine the language of texts in other languages", func() {
+		// Guarani
+		lang, _ := Detect(`Mba'éichapa reiko? Che aiko porã, ha nde? Ko ára ñambyasy mbohapy mitã oñembo'éva mbo'ehaópe, oikotevẽ pytyvõ tetã rembiapo ñemoñe'ẽ rehe.`)
+		Expect(lang).To(Equal(Undetermined))
+	})
+})
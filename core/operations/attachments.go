@@ -0,0 +1,48 @@
+package operations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+
+	"github.com/apex/log"
+)
+
+// attachmentLimits are the limits used when downloading attachments, which
+// are often larger than pages. Larger files are skipped.
+var attachmentLimits = fetchLimits{maxBodySize: 50 * 1024 * 1024, timeout: 30 * time.Second}
+
+// DownloadAttachments fetches the attachments of an article so that they can
+// be archived along with it, setting their size, SHA-256 hash and gzipped
+// content. Attachments that can't be fetched are logged and left as they are
+// since broken links are common on government websites.
+func DownloadAttachments(ctx context.Context, useCache bool, attachments []*core.Attachment) {
+	logger := log.FromContext(ctx)
+	for _, a := range attachments {
+		resp, err := fetchWithLimits(useCache, a.URL, attachmentLimits)
+		if err != nil {
+			logger.Warnf("Unable to download attachment %s: %s", a.URL, err)
+			continue
+		}
+
+		body := resp.body
+		content, err := gzipData(body)
+		if err != nil {
+			logger.Warnf("Unable to compress attachment %s: %s", a.URL, err)
+			continue
+		}
+		sum := sha256.Sum256(body)
+		a.Size = int64(len(body))
+		a.SHA256 = hex.EncodeToString(sum[:])
+		a.Content = content
+
+		mediaType, _, err := mime.ParseMediaType(resp.contentType)
+		if err == nil && mediaType != "application/octet-stream" && mediaType != "text/html" {
+			a.MIMEType = mediaType
+		}
+	}
+}
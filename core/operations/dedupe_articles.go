@@ -0,0 +1,71 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/duplicates"
+)
+
+type DedupeArticlesArgs struct {
+	// Paths are JSON payloads emitted by the article scraper or directories
+	// of them
+	Paths       []string
+	MaxDistance int
+}
+
+// DedupeArticles groups the articles found on args.Paths that are the same
+// content republished, identifying the original by the earliest publication
+// date.
+func DedupeArticles(args DedupeArticlesArgs) ([]*duplicates.Cluster, error) {
+	articles := []*core.ArticleData{}
+	for _, path := range args.Paths {
+		loaded, err := loadArticles(path)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, loaded...)
+	}
+	return duplicates.FindClusters(articles, args.MaxDistance), nil
+}
+
+// loadArticles loads the article data from a JSON file or from all JSON files
+// within a directory.
+func loadArticles(path string) ([]*core.ArticleData, error) {
+	articles := []*core.ArticleData{}
+	err := walkJSONFiles(path, func(filePath string, contents []byte) error {
+		data, err := core.ArticleDataFromJSON(contents)
+		if err != nil {
+			return fmt.Errorf("%s: %s", filePath, err)
+		}
+		articles = append(articles, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// walkJSONFiles calls fn with the contents of path or of each JSON file found
+// within it when path is a directory.
+func walkJSONFiles(path string, fn func(path string, contents []byte) error) error {
+	return filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(filePath)) != ".json" {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		return fn(filePath, contents)
+	})
+}
@@ -0,0 +1,67 @@
+package operations_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/duplicates"
+	. "github.com/fgrehm/brinfo/core/operations"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DedupeArticles", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "brinfo-dedupe")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(name, url, text string, publishedAt time.Time) {
+		payload, err := json.Marshal(&ArticleData{
+			URL:         url,
+			FullText:    text,
+			Fingerprint: duplicates.Fingerprint(text),
+			PublishedAt: &publishedAt,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), payload, 0644)).To(Succeed())
+	}
+
+	It("groups the articles found on the paths provided", func() {
+		text := "O Ministério da Saúde divulgou nesta terça-feira um novo boletim epidemiológico com dados atualizados sobre a pandemia no país, com os casos confirmados em cada estado."
+		write("agencia/boletim.json", "https://agenciabrasil.ebc.com.br/boletim", text, time.Date(2020, 5, 12, 10, 0, 0, 0, time.UTC))
+		write("es/boletim.json", "https://www.es.gov.br/boletim", text+" Fonte: Agência Brasil.", time.Date(2020, 5, 12, 15, 0, 0, 0, time.UTC))
+		write("es/vacinacao.json", "https://www.es.gov.br/vacinacao", "A vacinação contra a gripe começa nas escolas estaduais na próxima semana.", time.Date(2020, 5, 11, 10, 0, 0, 0, time.UTC))
+		Expect(ioutil.WriteFile(filepath.Join(dir, "es", "notes.txt"), []byte("ignored"), 0644)).To(Succeed())
+
+		clusters, err := DedupeArticles(DedupeArticlesArgs{
+			Paths:       []string{filepath.Join(dir, "agencia"), filepath.Join(dir, "es")},
+			MaxDistance: duplicates.DefaultMaxDistance,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Original.URL).To(Equal("https://agenciabrasil.ebc.com.br/boletim"))
+		Expect(clusters[0].Duplicates).To(HaveLen(1))
+		Expect(clusters[0].Duplicates[0].Article.URL).To(Equal("https://www.es.gov.br/boletim"))
+	})
+
+	It("fails on invalid payloads", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)).To(Succeed())
+
+		_, err := DedupeArticles(DedupeArticlesArgs{Paths: []string{dir}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -1,6 +1,10 @@
 package operations
 
 import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/apex/log"
@@ -13,41 +17,151 @@ func (*realClock) Now() time.Time {
 	return time.Now()
 }
 
+// FetchPage fetches a page so that it can be evaluated multiple times without
+// making new requests.
+func FetchPage(cache bool, url string) (*StoredPage, error) {
+	html, contentType, err := makeRequest(cache, url)
+	if err != nil {
+		return nil, err
+	}
+	return &StoredPage{URL: url, HTML: html, HTTPContentType: contentType}, nil
+}
+
+// maxRedirects is the same limit used by net/http, which colly stops
+// enforcing once a redirect handler is set.
+const maxRedirects = 10
+
+// Redirect is a response that sent the request to another URL.
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// response is what is kept from fetching a page.
+type response struct {
+	body        []byte
+	contentType string
+	// finalURL is where the page was fetched from after following redirects
+	finalURL  string
+	redirects []Redirect
+}
+
 func makeRequest(cache bool, url string) ([]byte, string, error) {
+	resp, err := fetch(cache, url)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.body, resp.contentType, nil
+}
+
+// fetchLimits bounds the time and size of the responses fetched.
+type fetchLimits struct {
+	maxBodySize int
+	timeout     time.Duration
+}
+
+// pageLimits are the limits used when fetching pages, the size is the default
+// one of colly.
+var pageLimits = fetchLimits{maxBodySize: 10 * 1024 * 1024, timeout: 5 * time.Second}
+
+// fetch requests url, following and recording the redirects on the way.
+func fetch(cache bool, url string) (*response, error) {
+	return fetchWithLimits(cache, url, pageLimits)
+}
+
+// fetchWithLimits works like fetch but with the limits provided. Responses
+// larger than limits.maxBodySize are reported as errors instead of being
+// truncated by colly.
+func fetchWithLimits(cache bool, url string, limits fetchLimits) (*response, error) {
 	opts := []colly.CollectorOption{
 		colly.UserAgent("Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:76.0) Gecko/20100101 Firefox/76.0"),
+		colly.MaxBodySize(limits.maxBodySize),
 	}
 
-	if cache {
+	// colly only caches the final response, so the redirects are kept next to
+	// it. Responses cached without them are fetched again.
+	var (
+		cached    *cachedRedirects
+		hit       bool
+		wasCached = cache && isCached(url)
+	)
+	if wasCached {
+		var err error
+		cached, err = loadCachedRedirects(url)
+		hit = err == nil
+	}
+	if cache && (hit || !wasCached) {
 		log.Info("Using cache")
-		opts = append(opts, colly.CacheDir("./.brinfo-cache/"))
+		opts = append(opts, colly.CacheDir(cacheDir))
 	}
 	c := colly.NewCollector(opts...)
-	c.SetRequestTimeout(5 * time.Second)
+	c.SetRequestTimeout(limits.timeout)
 
-	var (
-		body        []byte
-		contentType string
-		err         error
-	)
+	resp := &response{finalURL: url, redirects: []Redirect{}}
+	c.SetRedirectHandler(func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		status := 0
+		if req.Response != nil {
+			status = req.Response.StatusCode
+		}
+		resp.redirects = append(resp.redirects, Redirect{URL: via[len(via)-1].URL.String(), Status: status})
+		log.Debugf("Redirected to %s", req.URL)
+		return nil
+	})
 
+	var tooLarge error
 	c.OnResponse(func(r *colly.Response) {
 		log.Debugf("Status: %d", r.StatusCode)
+		if truncated(r, limits.maxBodySize) {
+			tooLarge = fmt.Errorf("response is larger than %d bytes", limits.maxBodySize)
+			return
+		}
 		if r.StatusCode == 200 {
-			body = r.Body
-			contentType = r.Headers.Get("Content-Type")
+			resp.body = r.Body
+			resp.contentType = utf8ContentType(r.Headers.Get("Content-Type"))
+			resp.finalURL = r.Request.URL.String()
 		}
 	})
 
-	err = c.Visit(url)
-	if err != nil {
-		return nil, "", err
+	if err := c.Visit(url); err != nil {
+		return nil, err
 	}
 	c.Wait()
+	if tooLarge != nil {
+		return nil, tooLarge
+	}
 
-	if err != nil {
-		return nil, "", err
+	if hit {
+		resp.finalURL, resp.redirects = cached.FinalURL, cached.Redirects
+	} else if cache && resp.body != nil {
+		if err := saveCachedRedirects(url, resp); err != nil {
+			log.Warnf("Unable to cache the redirects of %s: %s", url, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// truncated tells whether the body of r was cut at maxBodySize, which colly
+// does without any errors.
+func truncated(r *colly.Response, maxBodySize int) bool {
+	if len(r.Body) >= maxBodySize {
+		return true
 	}
+	length, err := strconv.Atoi(r.Headers.Get("Content-Length"))
+	return err == nil && length > maxBodySize
+}
 
-	return body, contentType, nil
+// utf8ContentType replaces the charset of the content type since colly
+// converts the body to UTF-8 when a charset is declared, so that extractors
+// don't decode it again.
+func utf8ContentType(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] == "" {
+		return contentType
+	}
+	params["charset"] = "utf-8"
+	return mime.FormatMediaType(mediaType, params)
 }
@@ -0,0 +1,56 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/fgrehm/brinfo/core/fixtures"
+	"github.com/fgrehm/brinfo/core/urls"
+)
+
+type RecordFixtureArgs struct {
+	UseCache         bool
+	URL              string
+	HTML             []byte
+	HTTPContentType  string
+	CustomExtractors string
+	Timezone         string
+	ContentEngine    string
+	URLRules         []urls.Rule
+	HashAlgorithm    string
+	Dir              string
+	Name             string
+}
+
+// RecordFixture stores the page found at args.URL along with the data
+// currently extracted from it on args.Dir. If args.HTML is set, no request is
+// made.
+func RecordFixture(ctx context.Context, args RecordFixtureArgs) (*fixtures.Fixture, error) {
+	html, httpContentType := args.HTML, args.HTTPContentType
+	if html == nil {
+		var err error
+		html, httpContentType, err = makeRequest(args.UseCache, args.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f := &fixtures.Fixture{
+		Name:             args.Name,
+		HTML:             html,
+		URL:              args.URL,
+		HTTPContentType:  httpContentType,
+		CustomExtractors: args.CustomExtractors,
+		Timezone:         args.Timezone,
+		ContentEngine:    args.ContentEngine,
+		URLRules:         args.URLRules,
+		HashAlgorithm:    args.HashAlgorithm,
+		RecordedAt:       (&realClock{}).Now(),
+	}
+	if err := f.Record(ctx); err != nil {
+		return nil, err
+	}
+	if err := fixtures.Save(args.Dir, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
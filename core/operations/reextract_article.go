@@ -0,0 +1,56 @@
+package operations
+
+import (
+	"context"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/scrapers"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
+)
+
+type ReextractArticleArgs struct {
+	Page          *StoredPage
+	Extractors    []Extractor
+	Location      *time.Location
+	URLRules      []urls.Rule
+	HashAlgorithm string
+	// Classifier should be shared by the pages of a source, see
+	// ScrapeArticleArgs.
+	Classifier *PageClassifier
+}
+
+type ReextractedArticle struct {
+	Data    *ArticleData
+	Changes []*FieldChange
+}
+
+// ReextractArticle runs the extractors over a page that has been fetched
+// before, comparing the result with the previous extraction when available.
+func ReextractArticle(ctx context.Context, args ReextractArticleArgs) (*ReextractedArticle, error) {
+	var clock Clock = &realClock{}
+	previous := args.Page.Previous
+	if previous != nil && !previous.FoundAt.IsZero() {
+		clock = FixedClock(previous.FoundAt)
+	}
+
+	scraper := NewArticleScraper(&ArticleScraperConfig{
+		Clock:         clock,
+		Extractors:    args.Extractors,
+		Location:      args.Location,
+		URLRules:      args.URLRules,
+		HashAlgorithm: args.HashAlgorithm,
+		Classifier:    args.Classifier,
+	})
+	data, err := scraper.Run(ctx, args.Page.HTML, args.Page.URL, args.Page.HTTPContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReextractedArticle{Data: data}
+	if previous != nil {
+		result.Changes = previous.Diff(data)
+	}
+	return result, nil
+}
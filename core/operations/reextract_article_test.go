@@ -0,0 +1,160 @@
+package operations_test
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/hashes"
+	. "github.com/fgrehm/brinfo/core/operations"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
+
+	"github.com/gocolly/colly/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReextractArticle", func() {
+	var (
+		ctx context.Context
+		dir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		dir, err = ioutil.TempDir("", "brinfo-reextract")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("extracts data from local HTML files", func() {
+		path := filepath.Join(dir, "article.html")
+		html := `<html><head><title>Local article</title></head><body><p>Article body</p></body></html>`
+		Expect(ioutil.WriteFile(path, []byte(html), 0644)).To(Succeed())
+
+		pages, err := LoadStoredPages(dir, "https://example.com/article")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(HaveLen(1))
+		Expect(pages[0].URL).To(Equal("https://example.com/article"))
+
+		result, err := ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       pages[0],
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.URL).To(Equal("https://example.com/article"))
+		Expect(result.Data.Title).To(Equal("Local article"))
+		Expect(result.Changes).To(BeNil())
+	})
+
+	It("uses the URL rules and the hash algorithm provided", func() {
+		page := &StoredPage{
+			URL:  "https://example.com/noticia.php?id=1&origem=home",
+			HTML: []byte(`<html><head><title>Local article</title></head><body><p>Article body</p></body></html>`),
+		}
+
+		result, err := ReextractArticle(ctx, ReextractArticleArgs{
+			Page:          page,
+			Extractors:    []Extractor{BasicArticle()},
+			URLRules:      []urls.Rule{{KeepParams: []string{"id"}}},
+			HashAlgorithm: hashes.AlgorithmSHA256,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.CanonicalURL).To(Equal("https://example.com/noticia.php?id=1"))
+		Expect(result.Data.URLHash).To(HaveLen(64))
+		Expect(result.Data.FullTextHash).To(HaveLen(64))
+	})
+
+	It("reports changes compared to archived articles", func() {
+		html := `<html><head><title>Archived article</title></head><body><p>Article body</p></body></html>`
+		foundAt := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+		page := &StoredPage{
+			URL:  "https://example.com/article",
+			HTML: []byte(html),
+		}
+		result, err := ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       page,
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		archived := result.Data
+		archived.Title = "Old title"
+		archived.FoundAt = foundAt
+		payload, err := json.Marshal(archived)
+		Expect(err).NotTo(HaveOccurred())
+		path := filepath.Join(dir, "article.json")
+		Expect(ioutil.WriteFile(path, payload, 0644)).To(Succeed())
+
+		pages, err := LoadStoredPages(path, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(HaveLen(1))
+		Expect(pages[0].URL).To(Equal("https://example.com/article"))
+		Expect(string(pages[0].HTML)).To(Equal(html))
+
+		result, err = ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       pages[0],
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.FoundAt).To(Equal(foundAt))
+		Expect(result.Changes).To(Equal([]*FieldChange{
+			{Field: "title", Before: "Old title", After: "Archived article"},
+		}))
+	})
+
+	It("does not decode archived pages twice", func() {
+		html := `<html><head><meta charset="iso-8859-1"><title>Publicação da Saúde</title></head><body><p>Article body</p></body></html>`
+		result, err := ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       &StoredPage{URL: "https://example.com/article", HTML: []byte(html), HTTPContentType: "text/html; charset=utf-8"},
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		payload, err := json.Marshal(result.Data)
+		Expect(err).NotTo(HaveOccurred())
+		path := filepath.Join(dir, "article.json")
+		Expect(ioutil.WriteFile(path, payload, 0644)).To(Succeed())
+
+		pages, err := LoadStoredPages(path, "")
+		Expect(err).NotTo(HaveOccurred())
+		result, err = ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       pages[0],
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.Title).To(Equal("Publicação da Saúde"))
+	})
+
+	It("converts cached responses to UTF-8", func() {
+		html := "<html><head><title>Publica\xe7\xe3o da Sa\xfade</title></head><body><p>Article body</p></body></html>"
+		headers := http.Header{"Content-Type": []string{"text/html; charset=iso-8859-1"}}
+		path := filepath.Join(dir, "response")
+		file, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gob.NewEncoder(file).Encode(&colly.Response{StatusCode: 200, Body: []byte(html), Headers: &headers})).To(Succeed())
+		Expect(file.Close()).To(Succeed())
+
+		pages, err := LoadStoredPages(path, "https://example.com/article")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pages).To(HaveLen(1))
+		Expect(pages[0].HTTPContentType).To(Equal("text/html; charset=utf-8"))
+
+		result, err := ReextractArticle(ctx, ReextractArticleArgs{
+			Page:       pages[0],
+			Extractors: []Extractor{BasicArticle()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.Title).To(Equal("Publicação da Saúde"))
+	})
+})
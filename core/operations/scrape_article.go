@@ -2,29 +2,80 @@ package operations
 
 import (
 	"context"
+	"time"
 
 	. "github.com/fgrehm/brinfo/core"
 	. "github.com/fgrehm/brinfo/core/scrapers"
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
 )
 
 type ScrapeArticleArgs struct {
-	UseCache   bool
-	URL        string
-	Extractors []Extractor
-	MergeWith  *ArticleData
+	UseCache        bool
+	URL             string
+	HTML            []byte
+	HTTPContentType string
+	Extractors      []Extractor
+	MergeWith       *ArticleData
+	Location        *time.Location
+	URLDatePatterns []string
+	URLRules        []urls.Rule
+	HashAlgorithm   string
+	// UseFinalURL makes the URL of the article, and therefore its hash, to be
+	// the one the page was served from after redirects instead of args.URL.
+	UseFinalURL bool
+	// DownloadAttachments makes the attachments found on the article to be
+	// fetched and archived along with its data.
+	DownloadAttachments bool
+	// Classifier should be shared by the articles scraped from a source so
+	// that error pages served on different URLs are detected.
+	Classifier *PageClassifier
 }
 
+// ScrapeArticle extracts article data from the page found at args.URL. If
+// args.HTML is set, no request is made and the HTML provided is used instead.
+// When the page is fetched, the URL it was served from and the redirects
+// followed are kept on the "final_url" and "redirects" extra fields.
 func ScrapeArticle(ctx context.Context, args ScrapeArticleArgs) (*ArticleData, error) {
-	html, httpContentType, err := makeRequest(args.UseCache, args.URL)
-	if err != nil {
-		return nil, err
+	html, httpContentType, url := args.HTML, args.HTTPContentType, args.URL
+	var resp *response
+	if html == nil {
+		var err error
+		resp, err = fetch(args.UseCache, args.URL)
+		if err != nil {
+			return nil, err
+		}
+		html, httpContentType = resp.body, resp.contentType
+		if args.UseFinalURL {
+			url = resp.finalURL
+		}
 	}
 
 	scraper := NewArticleScraper(&ArticleScraperConfig{
-		Clock:      &realClock{},
-		Extractors: args.Extractors,
-		MergeWith:  args.MergeWith,
+		Clock:           &realClock{},
+		Extractors:      args.Extractors,
+		MergeWith:       args.MergeWith,
+		Location:        args.Location,
+		URLDatePatterns: args.URLDatePatterns,
+		URLRules:        args.URLRules,
+		HashAlgorithm:   args.HashAlgorithm,
+		Classifier:      args.Classifier,
 	})
-	return scraper.Run(ctx, html, args.URL, httpContentType)
+	data, err := scraper.Run(ctx, html, url, httpContentType)
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil {
+		if data.Extra == nil {
+			data.Extra = map[string]interface{}{}
+		}
+		data.Extra["final_url"] = resp.finalURL
+		if len(resp.redirects) > 0 {
+			data.Extra["redirects"] = resp.redirects
+		}
+	}
+	if args.DownloadAttachments {
+		DownloadAttachments(ctx, args.UseCache, data.Attachments)
+	}
+	return data, nil
 }
@@ -0,0 +1,289 @@
+package operations_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/operations"
+	"github.com/fgrehm/brinfo/core/scrapers"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/fgrehm/brinfo/core/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScrapeArticle", func() {
+	var (
+		ctx context.Context
+		ts  *testutils.Server
+	)
+
+	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ts = testutils.NewTestServer()
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	scrape := func(url string) (*ArticleData, error) {
+		data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+			URL:        url,
+			Extractors: []Extractor{BasicArticle()},
+		})
+		return data, err
+	}
+
+	It("extracts data from articles with OpenGraph tags", func() {
+		ts.Articles = []*testutils.Article{{
+			ID:          "1",
+			Title:       "Governo anuncia medidas",
+			Excerpt:     "Novas medidas foram anunciadas nesta segunda",
+			Body:        "Corpo da notícia",
+			ImageURL:    "https://example.com/image.png",
+			PublishedAt: "2020-06-15T19:56:00-03:00",
+			ModifiedAt:  "2020-06-15T20:10:00-03:00",
+			Variant:     testutils.OpenGraphArticle,
+		}}
+
+		data, err := scrape(ts.ArticleURL(ts.Articles[0]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Governo anuncia medidas"))
+		Expect(data.Excerpt).To(Equal("Novas medidas foram anunciadas nesta segunda"))
+		Expect(data.FullText).To(Equal("Corpo da notícia"))
+		Expect(data.ImageURL).To(Equal("https://example.com/image.png"))
+		Expect(data.PublishedAt.Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc))).To(BeTrue())
+		Expect(data.ModifiedAt.Equal(time.Date(2020, 6, 15, 20, 10, 0, 0, brLoc))).To(BeTrue())
+	})
+
+	It("extracts dates from rnews markup", func() {
+		ts.Articles = []*testutils.Article{{
+			URL:         "/noticias/medidas",
+			Title:       "Governo anuncia medidas",
+			Body:        "Corpo da notícia",
+			PublishedAt: "15/06/2020 19h56",
+			Variant:     testutils.RNewsArticle,
+		}}
+
+		data, err := scrape(ts.ArticleURL(ts.Articles[0]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)))
+	})
+
+	It("decodes pages that are not encoded in UTF-8", func() {
+		ts.Charset = "iso-8859-1"
+		ts.Articles = []*testutils.Article{{
+			ID:    "1",
+			Title: "Secretaria de Saúde divulga relatório",
+			Body:  "O relatório foi apresentado nesta segunda.</p>\n<p>Ações de prevenção foram intensificadas em todos os municípios.",
+		}}
+
+		data, err := scrape(ts.ArticleURL(ts.Articles[0]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Secretaria de Saúde divulga relatório"))
+		Expect(data.FullText).To(ContainSubstring("Ações de prevenção"))
+	})
+
+	Context("with redirects", func() {
+		BeforeEach(func() {
+			ts.Articles = []*testutils.Article{{URL: "/new-path", Title: "Moved article"}}
+			ts.Redirects["/old-path"] = "/older-path"
+			ts.Redirects["/older-path"] = "/new-path"
+		})
+
+		It("follows them, keeping the requested URL", func() {
+			data, err := scrape(ts.URL() + "/old-path")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Title).To(Equal("Moved article"))
+			Expect(data.URL).To(Equal(ts.URL() + "/old-path"))
+			Expect(data.Extra["final_url"]).To(Equal(ts.URL() + "/new-path"))
+			Expect(data.Extra["redirects"]).To(Equal([]Redirect{
+				{URL: ts.URL() + "/old-path", Status: 301},
+				{URL: ts.URL() + "/older-path", Status: 301},
+			}))
+		})
+
+		It("uses the final URL when asked to", func() {
+			data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+				URL:         ts.URL() + "/old-path",
+				Extractors:  []Extractor{BasicArticle()},
+				UseFinalURL: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.URL).To(Equal(ts.URL() + "/new-path"))
+
+			direct, err := scrape(ts.URL() + "/new-path")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.URLHash).To(Equal(direct.URLHash))
+			Expect(direct.Extra).NotTo(HaveKey("redirects"))
+		})
+
+		Context("with the cache", func() {
+			var wd, dir string
+
+			BeforeEach(func() {
+				var err error
+				wd, err = os.Getwd()
+				Expect(err).NotTo(HaveOccurred())
+				dir, err = ioutil.TempDir("", "brinfo-cache")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Chdir(dir)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(os.Chdir(wd)).To(Succeed())
+				os.RemoveAll(dir)
+			})
+
+			It("keeps the final URL and the redirects of cached pages", func() {
+				args := ScrapeArticleArgs{
+					UseCache:    true,
+					URL:         ts.URL() + "/old-path",
+					Extractors:  []Extractor{BasicArticle()},
+					UseFinalURL: true,
+				}
+				first, err := ScrapeArticle(ctx, args)
+				Expect(err).NotTo(HaveOccurred())
+
+				ts.Redirects["/old-path"] = "/elsewhere"
+				second, err := ScrapeArticle(ctx, args)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(second.URL).To(Equal(ts.URL() + "/new-path"))
+				Expect(second.URLHash).To(Equal(first.URLHash))
+				Expect(second.Extra["final_url"]).To(Equal(first.Extra["final_url"]))
+				Expect(second.Extra["redirects"]).To(Equal(first.Extra["redirects"]))
+			})
+		})
+
+		It("stops after too many redirects", func() {
+			ts.Redirects["/loop"] = "/loop"
+
+			_, err := scrape(ts.URL() + "/loop")
+			Expect(err).To(MatchError(ContainSubstring("stopped after 10 redirects")))
+		})
+	})
+
+	Context("with attachments", func() {
+		BeforeEach(func() {
+			ts.Articles = []*testutils.Article{{
+				URL:   "/noticias/decreto",
+				Title: "Governo publica decreto",
+				Body:  `Leia o <a href="/arquivos/decreto.pdf">decreto</a> e o <a href="/arquivos/anexo.xls">anexo</a>.`,
+			}}
+			ts.Files["/arquivos/decreto.pdf"] = []byte("%PDF-1.4 decreto")
+		})
+
+		It("collects links to attachments", func() {
+			data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+				URL:        ts.ArticleURL(ts.Articles[0]),
+				Extractors: []Extractor{BasicArticle()},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Attachments).To(Equal([]*Attachment{
+				{URL: ts.URL() + "/arquivos/decreto.pdf", Text: "decreto", Extension: "pdf", MIMEType: "application/pdf"},
+				{URL: ts.URL() + "/arquivos/anexo.xls", Text: "anexo", Extension: "xls", MIMEType: "application/vnd.ms-excel"},
+			}))
+		})
+
+		It("downloads attachments when asked to", func() {
+			data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+				URL:                 ts.ArticleURL(ts.Articles[0]),
+				Extractors:          []Extractor{BasicArticle()},
+				DownloadAttachments: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Attachments).To(HaveLen(2))
+
+			pdf := data.Attachments[0]
+			Expect(pdf.Size).To(Equal(int64(16)))
+			Expect(pdf.SHA256).To(Equal("2bd741cd9811a3e9ed0c79aa25ee453b318882eda89f3f11ed2354d2dcc4a0b5"))
+			Expect(pdf.Content).NotTo(BeEmpty())
+
+			// Missing files are left as they are
+			xls := data.Attachments[1]
+			Expect(xls.SHA256).To(BeEmpty())
+			Expect(xls.Content).To(BeNil())
+		})
+
+		It("skips attachments that are too large instead of truncating them", func() {
+			ts.Files["/arquivos/anexo.xls"] = make([]byte, 50*1024*1024+1)
+
+			data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+				URL:                 ts.ArticleURL(ts.Articles[0]),
+				Extractors:          []Extractor{BasicArticle()},
+				DownloadAttachments: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Attachments).To(HaveLen(2))
+			Expect(data.Attachments[0].SHA256).NotTo(BeEmpty())
+
+			xls := data.Attachments[1]
+			Expect(xls.Size).To(BeZero())
+			Expect(xls.SHA256).To(BeEmpty())
+			Expect(xls.Content).To(BeNil())
+		})
+	})
+
+	It("shares the classifier provided between articles", func() {
+		ts.Articles = []*testutils.Article{
+			{URL: "/noticias/1", Title: "Portal do Governo", Body: "Conteúdo indisponível no momento."},
+			{URL: "/noticias/2", Title: "Portal do Governo", Body: "Conteúdo indisponível no momento."},
+		}
+		classifier := scrapers.NewPageClassifier()
+		scrapeWithClassifier := func(a *testutils.Article) *ArticleData {
+			data, err := ScrapeArticle(ctx, ScrapeArticleArgs{
+				URL:        ts.ArticleURL(a),
+				Extractors: []Extractor{BasicArticle()},
+				Classifier: classifier,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return data
+		}
+
+		Expect(scrapeWithClassifier(ts.Articles[0]).Extra["page_type"]).NotTo(Equal("error"))
+		data := scrapeWithClassifier(ts.Articles[1])
+		Expect(data.Extra["page_type"]).To(Equal("error"))
+		Expect(data.Extra["page_type_reasons"]).To(Equal([]string{"same title and text as " + ts.ArticleURL(ts.Articles[0])}))
+	})
+
+	It("extracts data from PDF files", func() {
+		ts.Files["/boletim.pdf"] = []byte(`%PDF-1.4
+1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj
+2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj
+3 0 obj << /Type /Page /Parent 2 0 R /Contents 4 0 R >> endobj
+4 0 obj << /Length 44 >>
+stream
+BT 72 760 Td (Boletim epidemiol\363gico) Tj ET
+endstream
+endobj
+5 0 obj << /CreationDate (D:20200615195600-03'00') >> endobj
+trailer << /Root 1 0 R /Info 5 0 R >>
+%%EOF`)
+
+		data, err := scrape(ts.URL() + "/boletim.pdf")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Boletim epidemiológico"))
+		Expect(data.PublishedAt.Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc))).To(BeTrue())
+	})
+
+	It("errors on HTTP errors", func() {
+		ts.StatusCodes["/broken"] = 500
+
+		_, err := scrape(ts.URL() + "/broken")
+		Expect(err).To(HaveOccurred())
+
+		_, err = scrape(ts.URL() + "/missing")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -1,141 +1,48 @@
 package operations
 
 import (
-	"bytes"
 	"context"
-	neturl "net/url"
-	"regexp"
 	"time"
 
 	"github.com/fgrehm/brinfo/core"
-	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
-
-	"github.com/PuerkitoBio/goquery"
+	"github.com/fgrehm/brinfo/core/scrapers"
 )
 
-// TODO: Move some of the logic in this file over to an abstraction on the scrapers package
-
 type ScrapeArticlesListingArgs struct {
 	UseCache             bool
 	URL                  string
+	HTML                 []byte
+	HTTPContentType      string
 	LinkContainer        string
 	URLExtractor         string
 	PublishedAtExtractor string
 	ImageURLExtractor    string
+	Location             *time.Location
 }
 
-type articlesListingScraper struct {
-	url       string
-	parsedURL *neturl.URL
-	extractor xt.Extractor
-	cache     bool
-}
-
+// ScrapeArticlesListing extracts links from the page found at args.URL. If
+// args.HTML is set, no request is made and args.URL is only used for
+// resolving relative links.
 func ScrapeArticlesListing(ctx context.Context, args ScrapeArticlesListingArgs) ([]*core.ArticleLink, error) {
-	if args.LinkContainer == "" {
-		panic("no link container")
-	}
-	if args.URLExtractor == "" {
-		panic("no url extractor")
-	}
-
-	extractors := map[string]xt.Extractor{}
-
-	e, err := xt.FromString(args.URLExtractor)
+	scraper, err := scrapers.NewArticleListScraper(&scrapers.ArticleListScraperConfig{
+		Clock:                &realClock{},
+		LinkContainer:        args.LinkContainer,
+		URLExtractor:         args.URLExtractor,
+		PublishedAtExtractor: args.PublishedAtExtractor,
+		ImageURLExtractor:    args.ImageURLExtractor,
+		Location:             args.Location,
+	})
 	if err != nil {
 		return nil, err
 	}
-	extractors["url"] = e
 
-	if args.PublishedAtExtractor != "" {
-		e, err = xt.FromString(args.PublishedAtExtractor)
+	html, httpContentType := args.HTML, args.HTTPContentType
+	if html == nil {
+		html, httpContentType, err = makeRequest(args.UseCache, args.URL)
 		if err != nil {
 			return nil, err
 		}
-		extractors["published_at"] = e
-	}
-
-	if args.ImageURLExtractor != "" {
-		e, err = xt.FromString(args.ImageURLExtractor)
-		if err != nil {
-			return nil, err
-		}
-		extractors["image_url"] = e
-	}
-
-	parsedURL, err := neturl.Parse(args.URL)
-	if err != nil {
-		panic(err)
-	}
-
-	scraper := &articlesListingScraper{
-		url:       args.URL,
-		parsedURL: parsedURL,
-		extractor: xt.StructuredList(args.LinkContainer, extractors),
-		cache:     args.UseCache,
-	}
-
-	return scraper.scrape(ctx)
-}
-
-func (s *articlesListingScraper) scrape(ctx context.Context) ([]*core.ArticleLink, error) {
-	body, _, err := makeRequest(s.cache, s.url)
-	if err != nil {
-		return nil, err
-	}
-
-	doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(body))
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := s.extractor.Extract(xt.ExtractorArgs{
-		Context: ctx,
-		URL:     s.url,
-		Root:    doc.Selection,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	list, ok := data.([]map[string]xt.ExtractorResult)
-	if !ok {
-		panic("Something went wrong")
-	}
-
-	ret := []*core.ArticleLink{}
-	for _, res := range list {
-		link := &core.ArticleLink{}
-
-		if res["published_at"] != nil {
-			pubAt := res["published_at"].(time.Time)
-			link.PublishedAt = &pubAt
-		}
-		if res["image_url"] != nil {
-			imageURL := s.fixRelativeURL(res["image_url"].(string))
-			link.ImageURL = &imageURL
-		}
-		link.URL = s.fixRelativeURL(res["url"].(string))
-
-		ret = append(ret, link)
-	}
-
-	return ret, nil
-}
-
-func (s *articlesListingScraper) fixRelativeURL(url string) string {
-	url = regexp.MustCompile(`\s*`).ReplaceAllString(url, "")
-	u, err := neturl.Parse(url)
-	if err != nil {
-		panic(err)
-	}
-
-	if u.Scheme == "" {
-		u.Scheme = s.parsedURL.Scheme
-	}
-	if u.Host == "" {
-		u.Host = s.parsedURL.Host
 	}
 
-	return u.String()
+	return scraper.Run(ctx, html, args.URL, httpContentType)
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
 	. "github.com/fgrehm/brinfo/core/operations"
 
 	"github.com/fgrehm/brinfo/core/testutils"
@@ -69,6 +70,25 @@ var _ = Describe("ScrapeArticlesListing", func() {
 		}))
 	})
 
+	It("only extracts links from the requested page", func() {
+		ts.PerPage = 2
+		ts.Articles = []*testutils.Article{
+			{URL: "/first-article"},
+			{URL: "/second-article"},
+			{URL: "/third-article"},
+		}
+
+		result, err := ScrapeArticlesListing(ctx, ScrapeArticlesListingArgs{
+			URL:           ts.URL() + "/articles?page=2",
+			LinkContainer: "ul li",
+			URLExtractor:  "a[href] | href",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal([]*ArticleLink{
+			{URL: ts.URL() + "/third-article"},
+		}))
+	})
+
 	It("supports extraction of article metadata", func() {
 		ts.Articles = []*testutils.Article{
 			{URL: "first-article", ImageURL: "/img.png"},
@@ -88,7 +108,7 @@ var _ = Describe("ScrapeArticlesListing", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(result).To(Equal([]*ArticleLink{
 			{URL: ts.URL() + "/first-article", PublishedAt: nil, ImageURL: &sampleImg},
-			{URL: ts.URL() + "/second-article", PublishedAt: &sampleDate, ImageURL: nil},
+			{URL: ts.URL() + "/second-article", PublishedAt: &sampleDate, PublishedAtPrecision: dates.PrecisionMinute, ImageURL: nil},
 		}))
 	})
 })
@@ -0,0 +1,259 @@
+package operations
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/net/html/charset"
+)
+
+const cacheDir = "./.brinfo-cache/"
+
+// cachedRedirectsSuffix is appended to the path of cached responses for
+// keeping the redirects followed when fetching them.
+const cachedRedirectsSuffix = ".redirects"
+
+// cachedRedirects is kept next to cached responses since colly caches them
+// under the URL requested, without the URL they were served from.
+type cachedRedirects struct {
+	FinalURL  string     `json:"final_url"`
+	Redirects []Redirect `json:"redirects"`
+}
+
+// StoredPage is a page that was fetched before and can be fed through the
+// scrapers again without hitting the site.
+type StoredPage struct {
+	Path            string
+	URL             string
+	HTML            []byte
+	HTTPContentType string
+	Previous        *core.ArticleData
+}
+
+// LoadStoredPages loads pages from a file or from all files within a
+// directory. JSON files are expected to be payloads previously emitted by the
+// article scraper, `.html` / `.htm` files are read as is and anything else is
+// treated as an entry of the HTTP cache. When the URL of the page can't be
+// determined from the file contents, baseURL is used and if that is empty a
+// file:// URL is generated.
+func LoadStoredPages(path, baseURL string) ([]*StoredPage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		page, err := loadStoredPage(path, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		return []*StoredPage{page}, nil
+	}
+
+	pages := []*StoredPage{}
+	err = filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(filePath, "~") || strings.HasSuffix(filePath, cachedRedirectsSuffix) {
+			return nil
+		}
+
+		page, err := loadStoredPage(filePath, baseURL)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// LoadCachedPage loads the page cached for the provided URL when scraping
+// with caching enabled.
+func LoadCachedPage(url string) (*StoredPage, error) {
+	page, err := loadCachedResponse(cachePath(url), url)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("'%s' not found on cache", url)
+	}
+	return page, err
+}
+
+// cachePath returns the path where colly caches the response for url.
+func cachePath(url string) string {
+	sum := sha1.Sum([]byte(url))
+	hash := hex.EncodeToString(sum[:])
+	return filepath.Join(cacheDir, hash[:2], hash)
+}
+
+func isCached(url string) bool {
+	_, err := os.Stat(cachePath(url))
+	return err == nil
+}
+
+func loadCachedRedirects(url string) (*cachedRedirects, error) {
+	contents, err := ioutil.ReadFile(cachePath(url) + cachedRedirectsSuffix)
+	if err != nil {
+		return nil, err
+	}
+	cached := &cachedRedirects{}
+	if err := json.Unmarshal(contents, cached); err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+func saveCachedRedirects(url string, resp *response) error {
+	contents, err := json.Marshal(&cachedRedirects{FinalURL: resp.finalURL, Redirects: resp.redirects})
+	if err != nil {
+		return err
+	}
+	path := cachePath(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path+cachedRedirectsSuffix, contents, 0644)
+}
+
+func loadStoredPage(path, baseURL string) (*StoredPage, error) {
+	url := baseURL
+	if url == "" {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		url = "file://" + filepath.ToSlash(absPath)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return loadArchivedArticle(path)
+	case ".html", ".htm":
+		html, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return &StoredPage{Path: path, URL: url, HTML: html, HTTPContentType: "text/html"}, nil
+	default:
+		return loadCachedResponse(path, url)
+	}
+}
+
+func loadArchivedArticle(path string) (*StoredPage, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := core.ArticleDataFromJSON(contents)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	// Articles scraped from PDF files keep the file instead of the HTML, which
+	// is archived after being converted to UTF-8
+	key, contentType := "html", "text/html; charset=utf-8"
+	if _, ok := data.Extra["pdf"]; ok {
+		key, contentType = "pdf", "application/pdf"
+	}
+	encodedHTML, ok := data.Extra[key].(string)
+	if !ok || encodedHTML == "" {
+		return nil, fmt.Errorf("%s: no html found on archived article", path)
+	}
+	gzippedHTML, err := base64.StdEncoding.DecodeString(encodedHTML)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	html, err := gunzip(gzippedHTML)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	return &StoredPage{Path: path, URL: data.URL, HTML: html, HTTPContentType: contentType, Previous: data}, nil
+}
+
+func loadCachedResponse(path, url string) (*StoredPage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	resp := &colly.Response{}
+	if err := gob.NewDecoder(file).Decode(resp); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: cached response has status %d", path, resp.StatusCode)
+	}
+
+	contentType := ""
+	if resp.Headers != nil {
+		contentType = resp.Headers.Get("Content-Type")
+	}
+	// Responses are cached before colly converts them to UTF-8
+	html, err := utf8Body(resp.Body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return &StoredPage{Path: path, URL: url, HTML: html, HTTPContentType: utf8ContentType(contentType)}, nil
+}
+
+// utf8Body converts body to UTF-8 when contentType declares a charset, the
+// same way colly does for the responses it fetches.
+func utf8Body(body []byte, contentType string) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] == "" {
+		return body, nil
+	}
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data to decompress")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
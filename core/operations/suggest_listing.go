@@ -0,0 +1,28 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/fgrehm/brinfo/core/scrapers"
+)
+
+type SuggestListingArgs struct {
+	UseCache bool
+	URL      string
+	HTML     []byte
+}
+
+// SuggestListing returns candidate ScrapeArticlesListing configurations for
+// the page found at args.URL, best ones first.
+func SuggestListing(ctx context.Context, args SuggestListingArgs) ([]*scrapers.ListingSuggestion, error) {
+	html := args.HTML
+	if html == nil {
+		var err error
+		html, _, err = makeRequest(args.UseCache, args.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scrapers.SuggestListingConfigs(ctx, html, args.URL)
+}
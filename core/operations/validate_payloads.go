@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"github.com/fgrehm/brinfo/core/schema"
+)
+
+type PayloadValidation struct {
+	Path     string
+	Problems []string
+}
+
+// ValidatePayloads checks the payloads found on paths, which are JSON files
+// emitted by the article scraper or directories of them, against the schema
+// of the version they were emitted on. Files that can't be validated, like
+// the ones that are not valid JSON, are reported as problems as well.
+func ValidatePayloads(paths []string) ([]*PayloadValidation, error) {
+	results := []*PayloadValidation{}
+	for _, path := range paths {
+		err := walkJSONFiles(path, func(filePath string, contents []byte) error {
+			problems, err := schema.Validate(contents)
+			if err != nil {
+				problems = []string{err.Error()}
+			}
+			results = append(results, &PayloadValidation{Path: filePath, Problems: problems})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package operations_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/operations"
+	"github.com/fgrehm/brinfo/core/schema"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidatePayloads", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "brinfo-validate")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reports the problems of each payload found", func() {
+		now := time.Now().UTC()
+		valid, err := schema.Marshal(&schema.Payload{
+			ArticleData: &ArticleData{URL: "https://example.com/noticia", FoundAt: now, PublishedAt: &now},
+			Key:         "noticia.json",
+			Source:      "source-guid",
+		}, schema.Version2)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "valid.json"), valid, 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"url": 1}`), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{`), 0644)).To(Succeed())
+
+		results, err := ValidatePayloads([]string{dir})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+
+		problems := map[string][]string{}
+		for _, r := range results {
+			problems[filepath.Base(r.Path)] = r.Problems
+		}
+		Expect(problems["valid.json"]).To(BeEmpty())
+		Expect(problems["invalid.json"]).To(ContainElement("url: expected string, got integer"))
+		Expect(problems["broken.json"]).To(HaveLen(1))
+	})
+
+	It("errors on paths that don't exist", func() {
+		_, err := ValidatePayloads([]string{filepath.Join(dir, "missing")})
+		Expect(err).To(HaveOccurred())
+	})
+})
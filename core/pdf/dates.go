@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+)
+
+var dateRegexp = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(?:([Zz+-])(?:(\d{2})'?(\d{2})?'?)?)?`)
+
+// ParseDate parses dates in the format used on the metadata of PDF files, like
+// D:20200512103000-03'00'. Dates without an offset are interpreted in loc.
+func ParseDate(str string, loc *time.Location) (*time.Time, dates.Precision, error) {
+	m := dateRegexp.FindStringSubmatch(str)
+	if m == nil {
+		return nil, "", fmt.Errorf("invalid PDF date '%s'", str)
+	}
+
+	parts := []int{0, 1, 1, 0, 0, 0}
+	precisions := []dates.Precision{dates.PrecisionYear, dates.PrecisionMonth, dates.PrecisionDay, dates.PrecisionDay, dates.PrecisionMinute, dates.PrecisionSecond}
+	precision := dates.PrecisionYear
+	for i := range parts {
+		if m[i+1] == "" {
+			break
+		}
+		parts[i], _ = strconv.Atoi(m[i+1])
+		precision = precisions[i]
+	}
+	if parts[1] < 1 || parts[1] > 12 || parts[2] < 1 || parts[2] > 31 || parts[3] > 23 || parts[4] > 59 || parts[5] > 59 {
+		return nil, "", fmt.Errorf("invalid PDF date '%s'", str)
+	}
+
+	switch m[7] {
+	case "Z", "z":
+		loc = time.UTC
+	case "+", "-":
+		hours, _ := strconv.Atoi(m[8])
+		minutes, _ := strconv.Atoi(m[9])
+		offset := hours*3600 + minutes*60
+		if m[7] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+	if loc == nil {
+		loc = dates.DefaultLocation
+	}
+
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, loc)
+	return &t, precision, nil
+}
@@ -0,0 +1,259 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// The types below represent the objects found on PDF files, numbers are always
+// parsed as float64 and strings are kept as raw bytes since their encoding
+// depends on where they are used.
+type (
+	name    string
+	keyword string
+	array   []interface{}
+	dict    map[name]interface{}
+	ref     struct{ num, gen int }
+)
+
+type stream struct {
+	dict dict
+	data []byte
+}
+
+// lexer reads PDF objects from buf, starting at pos.
+type lexer struct {
+	buf []byte
+	pos int
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.pos < len(l.buf) {
+		c := l.buf[l.pos]
+		if c == '%' {
+			for l.pos < len(l.buf) && l.buf[l.pos] != '\n' && l.buf[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isWhitespace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// next returns the next token, which is either a complete object or a
+// delimiter of arrays and dictionaries. nil is returned at the end of buf.
+func (l *lexer) next() interface{} {
+	l.skipWhitespace()
+	if l.pos >= len(l.buf) {
+		return nil
+	}
+
+	c := l.buf[l.pos]
+	switch {
+	case c == '/':
+		return l.readName()
+	case c == '(':
+		return l.readLiteralString()
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return keyword("<<")
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return keyword(">>")
+	case c == '<':
+		return l.readHexString()
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return keyword(string(c))
+	}
+
+	start := l.pos
+	for l.pos < len(l.buf) && !isWhitespace(l.buf[l.pos]) && !isDelimiter(l.buf[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		// Stray delimiter like ')' or '>'
+		l.pos++
+		return keyword(string(c))
+	}
+	token := string(l.buf[start:l.pos])
+	if n, err := strconv.ParseFloat(token, 64); err == nil && (c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')) {
+		return n
+	}
+	return keyword(token)
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.buf) {
+		return l.buf[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) readName() name {
+	l.pos++
+	var buf bytes.Buffer
+	for l.pos < len(l.buf) && !isWhitespace(l.buf[l.pos]) && !isDelimiter(l.buf[l.pos]) {
+		c := l.buf[l.pos]
+		if c == '#' && l.pos+2 < len(l.buf) {
+			if b, err := strconv.ParseUint(string(l.buf[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				buf.WriteByte(byte(b))
+				l.pos += 3
+				continue
+			}
+		}
+		buf.WriteByte(c)
+		l.pos++
+	}
+	return name(buf.String())
+}
+
+func (l *lexer) readLiteralString() []byte {
+	l.pos++
+	var buf bytes.Buffer
+	depth := 1
+	for l.pos < len(l.buf) {
+		c := l.buf[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf.Bytes()
+			}
+		case '\\':
+			if l.pos >= len(l.buf) {
+				return buf.Bytes()
+			}
+			c = l.buf[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.peek(0) == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					octal := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.buf) && l.buf[l.pos] >= '0' && l.buf[l.pos] <= '7'; i++ {
+						octal = octal*8 + int(l.buf[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(octal)
+				}
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return buf.Bytes()
+}
+
+func (l *lexer) readHexString() []byte {
+	l.pos++
+	digits := []byte{}
+	for l.pos < len(l.buf) && l.buf[l.pos] != '>' {
+		c := l.buf[l.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		b, _ := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		out[i] = byte(b)
+	}
+	return out
+}
+
+// readObject reads a complete object, assembling arrays, dictionaries and
+// indirect references from the tokens found.
+func (l *lexer) readObject() interface{} {
+	token := l.next()
+	switch t := token.(type) {
+	case keyword:
+		switch t {
+		case "[":
+			arr := array{}
+			for {
+				l.skipWhitespace()
+				if l.pos >= len(l.buf) {
+					return arr
+				}
+				if l.buf[l.pos] == ']' {
+					l.pos++
+					return arr
+				}
+				arr = append(arr, l.readObject())
+			}
+		case "<<":
+			d := dict{}
+			for {
+				l.skipWhitespace()
+				if l.pos >= len(l.buf) {
+					return d
+				}
+				if l.buf[l.pos] == '>' && l.peek(1) == '>' {
+					l.pos += 2
+					return d
+				}
+				key, ok := l.readObject().(name)
+				if !ok {
+					continue
+				}
+				d[key] = l.readObject()
+			}
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+	case float64:
+		// Integers might be the start of an indirect reference ("12 0 R")
+		if t == float64(int(t)) && t >= 0 {
+			saved := l.pos
+			gen, ok := l.next().(float64)
+			if ok && l.next() == keyword("R") {
+				return ref{int(t), int(gen)}
+			}
+			l.pos = saved
+		}
+	}
+	return token
+}
@@ -0,0 +1,389 @@
+// Package pdf extracts text and metadata from PDF files, like the bulletins
+// and ordinances that some government websites only publish as PDF.
+//
+// It is not a complete PDF implementation, only what is needed for reading the
+// text of documents generated by office suites: streams compressed with
+// FlateDecode, object streams, simple fonts using the standard encodings and
+// composite fonts with a ToUnicode map. Encrypted files are not supported.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Limits on how much data is decompressed from each stream and from the whole
+// file, so that small files with highly compressed streams can't exhaust the
+// memory available.
+const (
+	maxDecodedStreamSize = 16 * 1024 * 1024
+	maxDecodedFileSize   = 128 * 1024 * 1024
+)
+
+var (
+	ErrNotPDF    = errors.New("not a PDF file")
+	ErrEncrypted = errors.New("encrypted PDF files are not supported")
+
+	errDecodedTooLarge = errors.New("decoded data is too large")
+
+	objectRegexp  = regexp.MustCompile(`(?:^|[^0-9])(\d+)\s+(\d+)\s+obj\b`)
+	trailerRegexp = regexp.MustCompile(`trailer\s*<<`)
+)
+
+// Document holds the text and the metadata found on a PDF file. Dates are
+// kept as found on the file, use ParseDate for parsing them.
+type Document struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate string
+	ModDate      string
+	Pages        int
+	// Text has one line for each line of text found on the pages
+	Text string
+}
+
+// IsPDF tells if data looks like a PDF file.
+func IsPDF(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data[:min(len(data), 1024)], "\x00\t\r\n "), []byte("%PDF-"))
+}
+
+// Parse reads the text and the metadata of a PDF file. Malformed files that
+// make the parser panic are reported as errors.
+func Parse(data []byte) (doc *Document, err error) {
+	if !IsPDF(data) {
+		return nil, ErrNotPDF
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("malformed PDF file: %v", r)
+		}
+	}()
+	return parse(data)
+}
+
+func parse(data []byte) (*Document, error) {
+	f := &file{data: data, objects: map[int]interface{}{}}
+	f.readObjects()
+	if f.trailer["Encrypt"] != nil {
+		return nil, ErrEncrypted
+	}
+
+	doc := &Document{}
+	if info, ok := f.resolve(f.trailer["Info"]).(dict); ok {
+		doc.Title = f.textString(info["Title"])
+		doc.Author = f.textString(info["Author"])
+		doc.Subject = f.textString(info["Subject"])
+		doc.Keywords = f.textString(info["Keywords"])
+		doc.Creator = f.textString(info["Creator"])
+		doc.Producer = f.textString(info["Producer"])
+		doc.CreationDate = f.textString(info["CreationDate"])
+		doc.ModDate = f.textString(info["ModDate"])
+	}
+
+	pages := f.pages()
+	doc.Pages = len(pages)
+	texts := []string{}
+	for _, page := range pages {
+		if text := f.pageText(page); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	doc.Text = strings.Join(texts, "\n")
+
+	return doc, nil
+}
+
+// file holds the objects read from a PDF file, indexed by their numbers.
+type file struct {
+	data    []byte
+	objects map[int]interface{}
+	trailer dict
+	// decoded is how many bytes were decompressed from streams so far
+	decoded int
+}
+
+// readObjects scans the file for objects instead of relying on the cross
+// reference table, which is often broken on files found in the wild. Objects
+// found later override previous ones, like incremental updates are supposed
+// to.
+func (f *file) readObjects() {
+	f.trailer = dict{}
+	objectStreams := []*stream{}
+
+	for _, m := range objectRegexp.FindAllSubmatchIndex(f.data, -1) {
+		num, _ := strconv.Atoi(string(f.data[m[2]:m[3]]))
+		l := &lexer{buf: f.data, pos: m[1]}
+		obj := l.readObject()
+
+		if d, ok := obj.(dict); ok {
+			saved := l.pos
+			if l.next() == keyword("stream") {
+				s := &stream{dict: d, data: f.streamData(d, l.pos)}
+				obj = s
+				if d["Type"] == name("ObjStm") {
+					objectStreams = append(objectStreams, s)
+				}
+				if d["Type"] == name("XRef") {
+					f.mergeTrailer(d)
+				}
+			} else {
+				l.pos = saved
+			}
+		}
+		f.objects[num] = obj
+	}
+
+	for _, m := range trailerRegexp.FindAllIndex(f.data, -1) {
+		l := &lexer{buf: f.data, pos: m[0] + len("trailer")}
+		if d, ok := l.readObject().(dict); ok {
+			f.mergeTrailer(d)
+		}
+	}
+
+	for _, s := range objectStreams {
+		f.readObjectStream(s)
+	}
+}
+
+func (f *file) mergeTrailer(d dict) {
+	for _, key := range []name{"Root", "Info", "Encrypt"} {
+		if val, ok := d[key]; ok {
+			f.trailer[key] = val
+		}
+	}
+}
+
+// streamData returns the raw data of a stream that starts right after the
+// stream keyword at pos.
+func (f *file) streamData(d dict, pos int) []byte {
+	if pos < len(f.data) && f.data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(f.data) && f.data[pos] == '\n' {
+		pos++
+	}
+
+	if pos > len(f.data) {
+		return nil
+	}
+	if length, ok := d["Length"].(float64); ok && length >= 0 && length <= float64(len(f.data)-pos) {
+		end := pos + int(length)
+		rest := bytes.TrimLeft(f.data[end:min(len(f.data), end+20)], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return f.data[pos:end]
+		}
+	}
+
+	// The length is either an indirect reference or wrong
+	end := bytes.Index(f.data[pos:], []byte("endstream"))
+	if end < 0 {
+		return f.data[pos:]
+	}
+	return bytes.TrimRight(f.data[pos:pos+end], "\r\n")
+}
+
+// readObjectStream reads the objects compressed into s, which don't override
+// objects found directly on the file.
+func (f *file) readObjectStream(s *stream) {
+	data, err := f.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := s.dict["N"].(float64)
+	first, _ := s.dict["First"].(float64)
+	if first < 0 || first > float64(len(data)) {
+		return
+	}
+
+	header := &lexer{buf: data[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := header.next().(float64)
+		offset, ok2 := header.next().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+		if offset < 0 || first+offset >= float64(len(data)) {
+			continue
+		}
+		pos := int(first) + int(offset)
+		f.objects[int(num)] = (&lexer{buf: data, pos: pos}).readObject()
+	}
+}
+
+// resolve follows indirect references.
+func (f *file) resolve(obj interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = f.objects[r.num]
+	}
+	return nil
+}
+
+func (f *file) resolveDict(obj interface{}) dict {
+	switch o := f.resolve(obj).(type) {
+	case dict:
+		return o
+	case *stream:
+		return o.dict
+	}
+	return nil
+}
+
+// decode returns the decompressed data of a stream. Only FlateDecode is
+// supported since it is what is used for text.
+func (f *file) decode(s *stream) ([]byte, error) {
+	filters := []interface{}{}
+	switch filter := f.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = append(filters, filter)
+	case array:
+		filters = filter
+	}
+
+	data := s.data
+	for _, filter := range filters {
+		switch f.resolve(filter) {
+		case name("FlateDecode"), name("Fl"):
+			if f.decoded >= maxDecodedFileSize {
+				return nil, errDecodedTooLarge
+			}
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			limit := min(maxDecodedStreamSize, maxDecodedFileSize-f.decoded)
+			decoded, err := ioutil.ReadAll(io.LimitReader(zr, int64(limit)+1))
+			f.decoded += len(decoded)
+			if len(decoded) > limit {
+				return nil, errDecodedTooLarge
+			}
+			// Truncated streams are common, keep what could be decoded
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+	}
+	return data, nil
+}
+
+// pages returns the page dictionaries in order, with inherited resources
+// copied into them.
+func (f *file) pages() []dict {
+	root := f.resolveDict(f.trailer["Root"])
+	if root == nil {
+		return f.pagesByNumber()
+	}
+	pages := []dict{}
+	f.walkPages(root["Pages"], nil, &pages, 0)
+	if len(pages) == 0 {
+		return f.pagesByNumber()
+	}
+	return pages
+}
+
+func (f *file) walkPages(obj interface{}, resources interface{}, pages *[]dict, depth int) {
+	node := f.resolveDict(obj)
+	if node == nil || depth > 64 {
+		return
+	}
+	if r, ok := node["Resources"]; ok {
+		resources = r
+	}
+
+	kids, ok := f.resolve(node["Kids"]).(array)
+	if node["Type"] == name("Page") || !ok {
+		page := dict{}
+		for k, v := range node {
+			page[k] = v
+		}
+		page["Resources"] = resources
+		*pages = append(*pages, page)
+		return
+	}
+	for _, kid := range kids {
+		f.walkPages(kid, resources, pages, depth+1)
+	}
+}
+
+// pagesByNumber is used when the page tree can't be read, returning the page
+// objects in the order they were numbered.
+func (f *file) pagesByNumber() []dict {
+	nums := []int{}
+	for num, obj := range f.objects {
+		if d, ok := obj.(dict); ok && d["Type"] == name("Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+
+	pages := []dict{}
+	for _, num := range nums {
+		pages = append(pages, f.objects[num].(dict))
+	}
+	return pages
+}
+
+// textString decodes strings meant to be read by humans, which are either
+// UTF-16 with a byte order mark or PDFDocEncoding, approximated here by
+// Windows-1252.
+func (f *file) textString(obj interface{}) string {
+	b, ok := f.resolve(obj).([]byte)
+	if !ok {
+		return ""
+	}
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		return strings.TrimSpace(decodeUTF16(b[2:]))
+	}
+	if bytes.HasPrefix(b, []byte("\xef\xbb\xbf")) {
+		return strings.TrimSpace(string(b[3:]))
+	}
+	return strings.TrimSpace(decodeSingleByte(charmap.Windows1252, b))
+}
+
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func decodeSingleByte(cm *charmap.Charmap, b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		sb.WriteRune(cm.DecodeByte(c))
+	}
+	return sb.String()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,212 @@
+package pdf_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+	. "github.com/fgrehm/brinfo/core/pdf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("extracts text shown with simple fonts", func() {
+		data := buildPDF("/Info 5 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 6 0 R >> >> >>",
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 4 0 R >>",
+			streamObject("", `BT /F1 14 Tf 72 760 Td (Boletim Epidemiol\363gico) Tj
+0 -20 Td [(Secretaria da ) -50 (Sa\372de)] TJ
+0 -20 Td (Casos confirmados:) Tj 120 0 Td (1.234) Tj ET
+BT /F1 10 Tf 1 0 0 1 72 700 Tm (Atualizado \(parcial\)) Tj ET`),
+			`<< /Title (Boletim 12) /Author (SESA) /CreationDate (D:20200512103000-03'00') /ModDate (D:20200513) >>`,
+			"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		)
+
+		doc, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Pages).To(Equal(1))
+		Expect(doc.Title).To(Equal("Boletim 12"))
+		Expect(doc.Author).To(Equal("SESA"))
+		Expect(doc.CreationDate).To(Equal("D:20200512103000-03'00'"))
+		Expect(doc.ModDate).To(Equal("D:20200513"))
+		Expect(doc.Text).To(Equal("Boletim Epidemiológico\nSecretaria da Saúde\nCasos confirmados: 1.234\nAtualizado (parcial)"))
+	})
+
+	It("extracts text from compressed pages using composite fonts", func() {
+		cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0003> <0020>
+<0010> <00E7>
+endbfchar
+1 beginbfrange
+<0024> <003D> <0041>
+endbfrange
+2 beginbfrange
+<0044> <005D> <0061>
+<0060> <0061> [<00E3> <00E9>]
+endbfrange
+endcmap`
+		// "Vacinação" and "até amanhã" with the codes mapped above
+		text := fmt.Sprintf("BT /F1 12 Tf 72 760 Td <%s> Tj 0 -16 Td <%s> Tj ET",
+			"003900440046004C00510044001000600052",
+			"00440057006100030044005000440051004B0060")
+
+		data := buildPDF("/Info 3 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [4 0 R 6 0 R] /Count 2 >>",
+			`<< /Title <FEFF0043006F006D0075006E0069006300610064006F> >>`,
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 7 0 R >> >> /Contents [5 0 R] >>",
+			flateStreamObject("", text),
+			"<< /Type /Page /Parent 2 0 R /Contents 9 0 R >>",
+			"<< /Type /Font /Subtype /Type0 /BaseFont /Arial /Encoding /Identity-H /ToUnicode 8 0 R >>",
+			flateStreamObject("", cmap),
+			streamObject("", "BT 1 0 0 1 72 760 Tm (Segunda p\\341gina) Tj ET"),
+		)
+
+		doc, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Pages).To(Equal(2))
+		Expect(doc.Title).To(Equal("Comunicado"))
+		Expect(doc.Text).To(Equal("Vacinação\naté amanhã\nSegunda página"))
+	})
+
+	It("reads objects from object streams", func() {
+		objects := "6 0 7 57 << /Title (Portaria 123) /CreationDate (D:2020061510) >> << /Type /Font /Subtype /Type1 >>"
+		data := buildPDF("/Info 6 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 7 0 R >> >> /Contents 4 0 R >>",
+			streamObject("", "BT /F1 12 Tf 72 760 Td (Art. 1) Tj ET"),
+			flateStreamObject("/Type /ObjStm /N 2 /First 9", objects),
+		)
+
+		doc, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Title).To(Equal("Portaria 123"))
+		Expect(doc.CreationDate).To(Equal("D:2020061510"))
+		Expect(doc.Text).To(Equal("Art. 1"))
+	})
+
+	for _, length := range []string{"-1", "100000000000000000000", "9 0 R"} {
+		length := length
+
+		It("finds the end of streams with /Length "+length, func() {
+			data := buildPDF("",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				"<< /Length "+length+" >>\nstream\nBT 72 760 Td (Art. 1) Tj ET\nendstream",
+			)
+
+			doc, err := Parse(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Text).To(Equal("Art. 1"))
+		})
+	}
+
+	for _, c := range []struct{ dict, objects string }{
+		{"/N 1 /First -5", "6 0 << /Title (Portaria 123) >>"},
+		{"/N 1 /First 100000000000000000000", "6 0 << /Title (Portaria 123) >>"},
+		{"/N 1 /First 6", "6 -20 << /Title (Portaria 123) >>"},
+	} {
+		c := c
+
+		It("ignores object streams with "+c.dict+" and "+c.objects, func() {
+			data := buildPDF("/Info 6 0 R",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [] /Count 0 >>",
+				flateStreamObject("/Type /ObjStm "+c.dict, c.objects),
+			)
+
+			doc, err := Parse(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Title).To(BeEmpty())
+		})
+	}
+
+	It("skips streams that are too large when decoded", func() {
+		data := buildPDF("",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 5 0 R] >>",
+			flateStreamObject("", "BT 72 700 Td ("+strings.Repeat("a", 17*1024*1024)+") Tj ET"),
+			flateStreamObject("", "BT 72 760 Td (Boletim) Tj ET"),
+		)
+
+		doc, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Text).To(Equal("Boletim"))
+	})
+
+	It("stops decoding streams after too much data was decoded from the file", func() {
+		contents := []string{"6 0 R"}
+		for i := 0; i < 9; i++ {
+			contents = append(contents, "4 0 R")
+		}
+		contents = append(contents, "5 0 R")
+		data := buildPDF("",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents ["+strings.Join(contents, " ")+"] >>",
+			flateStreamObject("", strings.Repeat(" ", 15*1024*1024)),
+			flateStreamObject("", "BT 72 740 Td (Fim) Tj ET"),
+			flateStreamObject("", "BT 72 760 Td (Boletim) Tj ET"),
+		)
+
+		doc, err := Parse(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Text).To(Equal("Boletim"))
+	})
+
+	It("fails for files that are not PDFs", func() {
+		_, err := Parse([]byte("<html></html>"))
+		Expect(err).To(Equal(ErrNotPDF))
+	})
+
+	It("fails for encrypted files", func() {
+		data := buildPDF("/Encrypt 2 0 R", "<< /Type /Catalog >>", "<< /Filter /Standard >>")
+		_, err := Parse(data)
+		Expect(err).To(Equal(ErrEncrypted))
+	})
+})
+
+var _ = Describe("ParseDate", func() {
+	brLoc := time.FixedZone("BRT", -3*3600)
+
+	cases := []struct {
+		str       string
+		expected  time.Time
+		precision dates.Precision
+	}{
+		{"D:20200512103015-03'00'", time.Date(2020, 5, 12, 10, 30, 15, 0, brLoc), dates.PrecisionSecond},
+		{"D:20200512103015Z", time.Date(2020, 5, 12, 7, 30, 15, 0, brLoc), dates.PrecisionSecond},
+		{"D:202005121030+01'00", time.Date(2020, 5, 12, 6, 30, 0, 0, brLoc), dates.PrecisionMinute},
+		{"D:20200512", time.Date(2020, 5, 12, 0, 0, 0, 0, brLoc), dates.PrecisionDay},
+		{"20200512103015", time.Date(2020, 5, 12, 10, 30, 15, 0, brLoc), dates.PrecisionSecond},
+		{"D:202005", time.Date(2020, 5, 1, 0, 0, 0, 0, brLoc), dates.PrecisionMonth},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("parses "+c.str, func() {
+			t, precision, err := ParseDate(c.str, brLoc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.Equal(c.expected)).To(BeTrue(), "expected %s, got %s", c.expected, t)
+			Expect(precision).To(Equal(c.precision))
+		})
+	}
+
+	It("fails for invalid dates", func() {
+		_, _, err := ParseDate("D:20201312", brLoc)
+		Expect(err).To(HaveOccurred())
+		_, _, err = ParseDate("ontem", brLoc)
+		Expect(err).To(HaveOccurred())
+	})
+})
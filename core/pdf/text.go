@@ -0,0 +1,380 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var blankLinesRegexp = regexp.MustCompile(`\n{2,}`)
+
+// font decodes the strings shown with it into text.
+type font struct {
+	toUnicode map[string]string
+	// codeLength is the number of bytes used for each character code, 2 for
+	// composite fonts and 1 for simple fonts
+	codeLength int
+	charmap    *charmap.Charmap
+}
+
+func (ft *font) decode(b []byte) string {
+	if ft.toUnicode == nil {
+		if ft.codeLength == 2 {
+			// Glyph ids can't be mapped to text without a ToUnicode map
+			return ""
+		}
+		return decodeSingleByte(ft.charmap, b)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(b); {
+		n := ft.codeLength
+		if i+n > len(b) {
+			n = len(b) - i
+		}
+		code := string(b[i : i+n])
+		if text, ok := ft.toUnicode[code]; ok {
+			sb.WriteString(text)
+		} else if ft.codeLength == 1 {
+			sb.WriteRune(ft.charmap.DecodeByte(b[i]))
+		}
+		i += n
+	}
+	return sb.String()
+}
+
+var defaultFont = &font{codeLength: 1, charmap: charmap.Windows1252}
+
+// textState tracks what is needed for breaking the text shown into lines.
+type textState struct {
+	f        *file
+	buf      strings.Builder
+	font     *font
+	fonts    map[name]*font
+	y        float64
+	lastY    float64
+	hasY     bool
+	depth    int
+	lineText bool
+}
+
+func (f *file) pageText(page dict) string {
+	ts := &textState{f: f, font: defaultFont, fonts: map[name]*font{}}
+	ts.run(f.pageContents(page), f.resolveDict(page["Resources"]))
+
+	lines := []string{}
+	for _, line := range strings.Split(ts.buf.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		lines = append(lines, line)
+	}
+	text := blankLinesRegexp.ReplaceAllString(strings.Join(lines, "\n"), "\n")
+	return strings.TrimSpace(text)
+}
+
+// pageContents returns the decoded content streams of a page concatenated.
+func (f *file) pageContents(page dict) []byte {
+	streams := []interface{}{}
+	switch contents := f.resolve(page["Contents"]).(type) {
+	case *stream:
+		streams = append(streams, contents)
+	case array:
+		streams = contents
+	}
+
+	var buf bytes.Buffer
+	for _, obj := range streams {
+		s, ok := f.resolve(obj).(*stream)
+		if !ok {
+			continue
+		}
+		data, err := f.decode(s)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func (ts *textState) newLine() {
+	if ts.lineText {
+		ts.buf.WriteByte('\n')
+		ts.lineText = false
+	}
+}
+
+func (ts *textState) show(b []byte) {
+	text := ts.font.decode(b)
+	if text == "" {
+		return
+	}
+	ts.buf.WriteString(text)
+	ts.lineText = true
+}
+
+// moveTo breaks lines when the text moves vertically.
+func (ts *textState) moveTo(y float64) {
+	if ts.hasY && math.Abs(y-ts.lastY) > 1 {
+		ts.newLine()
+	}
+	ts.lastY, ts.hasY = y, true
+}
+
+// run interprets the text operators of a content stream, ignoring everything
+// related to graphics.
+func (ts *textState) run(content []byte, resources dict) {
+	if ts.depth > 8 {
+		return
+	}
+	ts.depth++
+	defer func() { ts.depth-- }()
+
+	l := &lexer{buf: content}
+	operands := []interface{}{}
+	for {
+		obj := l.readObject()
+		if obj == nil && l.pos >= len(l.buf) {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok || op == "[" || op == "<<" {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "BI":
+			// Inline images have binary data that can't be tokenized
+			end := bytes.Index(l.buf[l.pos:], []byte("EI"))
+			if end < 0 {
+				return
+			}
+			l.pos += end + 2
+		case "BT":
+			ts.y = 0
+		case "Tf":
+			if len(operands) >= 2 {
+				if fontName, ok := operands[len(operands)-2].(name); ok {
+					ts.font = ts.loadFont(resources, fontName)
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := operands[len(operands)-2].(float64)
+				ty, _ := operands[len(operands)-1].(float64)
+				ts.y += ty
+				ts.moveTo(ts.y)
+				if math.Abs(ty) <= 1 && tx > 0 && ts.lineText {
+					ts.buf.WriteByte(' ')
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				ts.y, _ = operands[len(operands)-1].(float64)
+				ts.moveTo(ts.y)
+			}
+		case "T*":
+			ts.newLine()
+		case "Tj":
+			if len(operands) >= 1 {
+				if b, ok := operands[len(operands)-1].([]byte); ok {
+					ts.show(b)
+				}
+			}
+		case "'", "\"":
+			ts.newLine()
+			if len(operands) >= 1 {
+				if b, ok := operands[len(operands)-1].([]byte); ok {
+					ts.show(b)
+				}
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, ok := operands[len(operands)-1].(array); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case []byte:
+							ts.show(v)
+						case float64:
+							// Large negative adjustments are used as spaces
+							if v < -200 {
+								ts.buf.WriteByte(' ')
+							}
+						}
+					}
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 {
+				if xName, ok := operands[len(operands)-1].(name); ok {
+					ts.runXObject(resources, xName)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// runXObject extracts the text of forms drawn on the page.
+func (ts *textState) runXObject(resources dict, xName name) {
+	xobjects := ts.f.resolveDict(resources["XObject"])
+	s, ok := ts.f.resolve(xobjects[xName]).(*stream)
+	if !ok || s.dict["Subtype"] != name("Form") {
+		return
+	}
+	data, err := ts.f.decode(s)
+	if err != nil {
+		return
+	}
+	formResources := ts.f.resolveDict(s.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	savedFonts := ts.fonts
+	ts.fonts = map[name]*font{}
+	ts.run(data, formResources)
+	ts.fonts = savedFonts
+}
+
+func (ts *textState) loadFont(resources dict, fontName name) *font {
+	if ft, ok := ts.fonts[fontName]; ok {
+		return ft
+	}
+	ft := ts.f.font(ts.f.resolveDict(ts.f.resolveDict(resources["Font"])[fontName]))
+	ts.fonts[fontName] = ft
+	return ft
+}
+
+func (f *file) font(d dict) *font {
+	if d == nil {
+		return defaultFont
+	}
+
+	ft := &font{codeLength: 1, charmap: charmap.Windows1252}
+	if d["Subtype"] == name("Type0") {
+		ft.codeLength = 2
+	}
+	switch f.resolve(d["Encoding"]) {
+	case name("MacRomanEncoding"):
+		ft.charmap = charmap.Macintosh
+	case name("Identity-H"), name("Identity-V"):
+		ft.codeLength = 2
+	}
+	if enc, ok := f.resolve(d["Encoding"]).(dict); ok && enc["BaseEncoding"] == name("MacRomanEncoding") {
+		ft.charmap = charmap.Macintosh
+	}
+
+	if s, ok := f.resolve(d["ToUnicode"]).(*stream); ok {
+		if data, err := f.decode(s); err == nil {
+			ft.toUnicode, ft.codeLength = parseCMap(data, ft.codeLength)
+		}
+	}
+	return ft
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap. The
+// length of the codes is taken from the mappings, falling back to
+// codeLength.
+func parseCMap(data []byte, codeLength int) (map[string]string, int) {
+	mapping := map[string]string{}
+	l := &lexer{buf: data}
+	operands := []interface{}{}
+	inChar, inRange := false, false
+	setLength := func(code []byte) {
+		if len(code) > 0 {
+			codeLength = len(code)
+		}
+	}
+
+	for {
+		obj := l.readObject()
+		if obj == nil && l.pos >= len(l.buf) {
+			break
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "beginbfchar":
+			inChar = true
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					setLength(src)
+					mapping[string(src)] = decodeUTF16(dst)
+				}
+			}
+			inChar = false
+		case "beginbfrange":
+			inRange = true
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				setLength(lo)
+				addRange(mapping, lo, hi, operands[i+2])
+			}
+			inRange = false
+		}
+		if !inChar && !inRange || op == "beginbfchar" || op == "beginbfrange" {
+			operands = operands[:0]
+		}
+	}
+	return mapping, codeLength
+}
+
+func addRange(mapping map[string]string, lo, hi []byte, dst interface{}) {
+	start, end := bytesToInt(lo), bytesToInt(hi)
+	if end < start || end-start > 0xffff {
+		return
+	}
+	for code := start; code <= end; code++ {
+		src := intToBytes(code, len(lo))
+		switch d := dst.(type) {
+		case []byte:
+			// The last byte of the destination is incremented for each code
+			out := append([]byte{}, d...)
+			if len(out) >= 2 {
+				v := int(out[len(out)-2])<<8 | int(out[len(out)-1]) + (code - start)
+				out[len(out)-2], out[len(out)-1] = byte(v>>8), byte(v)
+			}
+			mapping[string(src)] = decodeUTF16(out)
+		case array:
+			if code-start < len(d) {
+				if b, ok := d[code-start].([]byte); ok {
+					mapping[string(src)] = decodeUTF16(b)
+				}
+			}
+		}
+	}
+}
+
+func bytesToInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+func intToBytes(n, length int) []byte {
+	b := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return b
+}
@@ -0,0 +1,235 @@
+// Package schema defines the versioned JSON format articles are emitted in
+// and the JSON Schema of each version, generated from the Go types so that
+// both can't drift apart.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	"github.com/fgrehm/brinfo/core/validation"
+)
+
+// Versions of the output format. Version2 emits modified dates on
+// modified_at instead of updated_at, consumers can move to it at their own
+// pace since DefaultVersion stays on Version1.
+const (
+	Version1       = "1"
+	Version2       = "2"
+	LatestVersion  = Version2
+	DefaultVersion = Version1
+)
+
+var Versions = []string{Version1, Version2}
+
+// renames maps the JSON fields of ArticleData to their names on each version
+// that diverges from Version1.
+var renames = map[string]map[string]string{
+	Version2: {
+		"updated_at":            "modified_at",
+		"updated_at_confidence": "modified_at_confidence",
+		"updated_at_precision":  "modified_at_precision",
+	},
+}
+
+// descriptions documents the fields whose meaning isn't obvious from their
+// names.
+var descriptions = map[string]string{
+	"brinfo":              "Data kept by the scraper, like the gzipped page the article was extracted from and the redirects followed",
+	"extra":               "Data provided by the caller along with the article",
+	"key":                 "Path the payload is stored at",
+	"source_guid":         "Identifier of the source the article was scraped from",
+	"schema_version":      "Version of this schema, payloads without it are on version 1",
+	"canonical_url":       "Normalized URL that identifies the article, url_hash is computed from it",
+	"fingerprint":         "SimHash of the full text, used for finding republished articles",
+	"validation":          "Problems found by the validation rules, articles with errors should not be ingested",
+	"language":            "ISO 639-1 code of the language of the full text, und when it couldn't be determined",
+	"language_confidence": "Share of the function words found on the full text that belong to the language detected",
+	"reading_minutes":     "Time it takes to read the full text, rounded up",
+}
+
+// Payload is what is emitted for each article scraped.
+type Payload struct {
+	*core.ArticleData
+
+	Key           string                 `json:"key"`
+	Source        string                 `json:"source_guid"`
+	Extra         map[string]interface{} `json:"extra,omitempty"`
+	SchemaVersion string                 `json:"schema_version,omitempty"`
+	Validation    []*validation.Result   `json:"validation,omitempty"`
+}
+
+func checkVersion(version string) error {
+	for _, v := range Versions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown schema version '%s', expected one of %s", version, strings.Join(Versions, ", "))
+}
+
+// Marshal returns the indented JSON of p on the version requested.
+func Marshal(p *Payload, version string) ([]byte, error) {
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+	versioned := *p
+	versioned.SchemaVersion = version
+
+	data, err := json.Marshal(&versioned)
+	if err != nil {
+		return nil, err
+	}
+	if renames[version] == nil {
+		return indent(data)
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for from, to := range renames[version] {
+		if value, ok := fields[from]; ok {
+			fields[to] = value
+			delete(fields, from)
+		}
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+	return indent(data)
+}
+
+func indent(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Schema returns the JSON Schema of the payloads emitted on version.
+func Schema(version string) (map[string]interface{}, error) {
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+
+	s := typeSchema(reflect.TypeOf(Payload{}))
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = "brinfo article, version " + version
+
+	properties := s["properties"].(map[string]interface{})
+	required := s["required"].([]string)
+	for from, to := range renames[version] {
+		properties[to] = properties[from]
+		delete(properties, from)
+		for i, name := range required {
+			if name == from {
+				required[i] = to
+			}
+		}
+	}
+	properties["schema_version"].(map[string]interface{})["enum"] = []string{version}
+	return s, nil
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	precisionType = reflect.TypeOf(dates.Precision(""))
+	severityType  = reflect.TypeOf(validation.Severity(""))
+	bytesType     = reflect.TypeOf([]byte{})
+)
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case precisionType:
+		return map[string]interface{}{"type": "string", "enum": []string{
+			string(dates.PrecisionYear), string(dates.PrecisionMonth), string(dates.PrecisionDay),
+			string(dates.PrecisionMinute), string(dates.PrecisionSecond),
+		}}
+	case severityType:
+		return map[string]interface{}{"type": "string", "enum": []string{
+			string(validation.SeverityError), string(validation.SeverityWarning),
+		}}
+	case bytesType:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		addFields(t, properties, &required)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	// Interfaces accept anything
+	return map[string]interface{}{}
+}
+
+// addFields adds the fields of t to properties the way encoding/json
+// serializes them, including the ones of embedded structs. Fields that can be
+// null, like pointers, maps and slices without omitempty, accept null.
+func addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name, omitEmpty := parts[0], len(parts) > 1 && parts[1] == "omitempty"
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			addFields(embedded, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s := typeSchema(field.Type)
+		kind := field.Type.Kind()
+		if !omitEmpty && (kind == reflect.Ptr || kind == reflect.Map || (kind == reflect.Slice && field.Type != bytesType)) {
+			s["type"] = []interface{}{s["type"], "null"}
+		}
+		if description, ok := descriptions[name]; ok {
+			s["description"] = description
+		}
+		properties[name] = s
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	. "github.com/fgrehm/brinfo/core/schema"
+	"github.com/fgrehm/brinfo/core/validation"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func newPayload() *Payload {
+	publishedAt := time.Date(2020, 5, 12, 10, 30, 0, 0, time.UTC)
+	modifiedAt := publishedAt.Add(2 * time.Hour)
+	return &Payload{
+		ArticleData: &core.ArticleData{
+			Extra:                map[string]interface{}{"final_url": "https://example.com/noticia"},
+			URL:                  "https://example.com/noticia",
+			URLHash:              "url-hash",
+			Title:                "Article title",
+			FullText:             "text",
+			FullTextHash:         "text-hash",
+			FoundAt:              publishedAt,
+			PublishedAt:          &publishedAt,
+			PublishedAtPrecision: dates.PrecisionMinute,
+			ModifiedAt:           &modifiedAt,
+			ModifiedAtPrecision:  dates.PrecisionMinute,
+			Media:                []*core.Media{{Type: "image", URL: "https://example.com/foto.jpg", Width: 640}},
+		},
+		Key:    "example/noticia.json",
+		Source: "source-guid",
+	}
+}
+
+func decode(data []byte) map[string]interface{} {
+	fields := map[string]interface{}{}
+	Expect(json.Unmarshal(data, &fields)).To(Succeed())
+	return fields
+}
+
+// withField returns data with name set to value, or removed if value is nil.
+func withField(data []byte, name string, value interface{}) []byte {
+	fields := decode(data)
+	if value == nil {
+		delete(fields, name)
+	} else {
+		fields[name] = value
+	}
+	changed, err := json.Marshal(fields)
+	Expect(err).NotTo(HaveOccurred())
+	return changed
+}
+
+var _ = Describe("Marshal", func() {
+	It("emits modified dates on updated_at on version 1", func() {
+		data, err := Marshal(newPayload(), Version1)
+		Expect(err).NotTo(HaveOccurred())
+
+		fields := decode(data)
+		Expect(fields).To(HaveKeyWithValue("schema_version", "1"))
+		Expect(fields).To(HaveKeyWithValue("updated_at", "2020-05-12T12:30:00Z"))
+		Expect(fields).To(HaveKeyWithValue("updated_at_precision", "minute"))
+		Expect(fields).NotTo(HaveKey("modified_at"))
+		Expect(fields).To(HaveKeyWithValue("key", "example/noticia.json"))
+		Expect(fields).To(HaveKeyWithValue("source_guid", "source-guid"))
+	})
+
+	It("emits modified dates on modified_at on version 2", func() {
+		data, err := Marshal(newPayload(), Version2)
+		Expect(err).NotTo(HaveOccurred())
+
+		fields := decode(data)
+		Expect(fields).To(HaveKeyWithValue("schema_version", "2"))
+		Expect(fields).To(HaveKeyWithValue("modified_at", "2020-05-12T12:30:00Z"))
+		Expect(fields).To(HaveKeyWithValue("modified_at_precision", "minute"))
+		Expect(fields).NotTo(HaveKey("updated_at"))
+		Expect(fields).NotTo(HaveKey("updated_at_precision"))
+	})
+
+	It("doesn't change the payload", func() {
+		p := newPayload()
+		_, err := Marshal(p, Version2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.SchemaVersion).To(BeEmpty())
+	})
+
+	It("round trips through ArticleDataFromJSON", func() {
+		for _, version := range Versions {
+			data, err := Marshal(newPayload(), version)
+			Expect(err).NotTo(HaveOccurred())
+
+			article, err := core.ArticleDataFromJSON(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(article.ModifiedAt).NotTo(BeNil())
+			Expect(*article.ModifiedAt).To(BeTemporally("==", *newPayload().ModifiedAt))
+			Expect(article.ModifiedAtPrecision).To(Equal(dates.PrecisionMinute))
+		}
+	})
+
+	It("errors on unknown versions", func() {
+		_, err := Marshal(newPayload(), "3")
+		Expect(err).To(MatchError("unknown schema version '3', expected one of 1, 2"))
+	})
+})
+
+var _ = Describe("Schema", func() {
+	It("requires the fields that are always emitted", func() {
+		s, err := Schema(Version1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(s["required"]).To(ContainElement("url"))
+		Expect(s["required"]).To(ContainElement("key"))
+		Expect(s["required"]).To(ContainElement("updated_at"))
+		Expect(s["required"]).NotTo(ContainElement("canonical_url"))
+		Expect(s["additionalProperties"]).To(BeFalse())
+	})
+
+	It("renames modified dates on version 2", func() {
+		s, err := Schema(Version2)
+		Expect(err).NotTo(HaveOccurred())
+
+		properties := s["properties"].(map[string]interface{})
+		Expect(properties).To(HaveKey("modified_at"))
+		Expect(properties).To(HaveKey("modified_at_precision"))
+		Expect(properties).NotTo(HaveKey("updated_at"))
+		Expect(s["required"]).To(ContainElement("modified_at"))
+		Expect(s["required"]).NotTo(ContainElement("updated_at"))
+	})
+
+	It("describes dates and nullable fields", func() {
+		s, err := Schema(Version1)
+		Expect(err).NotTo(HaveOccurred())
+
+		properties := s["properties"].(map[string]interface{})
+		Expect(properties["found_at"]).To(HaveKeyWithValue("format", "date-time"))
+		Expect(properties["published_at"]).To(HaveKeyWithValue("type", ConsistOf("string", "null")))
+		Expect(properties["schema_version"]).To(HaveKeyWithValue("enum", []string{"1"}))
+	})
+
+	It("is serializable", func() {
+		for _, version := range Versions {
+			s, err := Schema(version)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = json.Marshal(s)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("errors on unknown versions", func() {
+		_, err := Schema("0")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Validate", func() {
+	var (
+		v1 []byte
+		v2 []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+		v1, err = Marshal(newPayload(), Version1)
+		Expect(err).NotTo(HaveOccurred())
+		v2, err = Marshal(newPayload(), Version2)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts the payloads emitted", func() {
+		for _, data := range [][]byte{v1, v2} {
+			problems, err := Validate(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		}
+	})
+
+	It("validates payloads without a version as version 1", func() {
+		problems, err := Validate(withField(v1, "schema_version", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+
+		problems, err = Validate(withField(v2, "schema_version", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ContainElement("payload: missing required field 'updated_at'"))
+		Expect(problems).To(ContainElement("payload: unknown field 'modified_at'"))
+	})
+
+	It("accepts null on nullable fields", func() {
+		problems, err := Validate([]byte(strings.Replace(string(v2), `"modified_at": "2020-05-12T12:30:00Z"`, `"modified_at": null`, 1)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("reports the problems found", func() {
+		data := withField(v1, "title", 42)
+		data = withField(data, "url", nil)
+		data = withField(data, "found_at", "yesterday")
+		data = withField(data, "published_at_precision", "week")
+		data = withField(data, "updated", "2020-05-12")
+		data = withField(data, "media", []interface{}{map[string]interface{}{"type": "image", "url": "x", "width": "640"}})
+
+		problems, err := Validate(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ConsistOf(
+			"payload: missing required field 'url'",
+			"found_at: invalid date-time 'yesterday'",
+			"media[0].width: expected integer, got string",
+			"published_at_precision: expected one of year, month, day, minute, second, got 'week'",
+			"title: expected string, got integer",
+			"payload: unknown field 'updated'",
+		))
+	})
+
+	It("checks validation results", func() {
+		p := newPayload()
+		p.Validation = []*validation.Result{{Rule: "image_present", Severity: validation.SeverityWarning, Message: "missing image_url"}}
+		data, err := Marshal(p, Version2)
+		Expect(err).NotTo(HaveOccurred())
+
+		problems, err := Validate(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+
+		problems, err = Validate([]byte(strings.Replace(string(data), `"warning"`, `"off"`, 1)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(ConsistOf("validation[0].severity: expected one of error, warning, got 'off'"))
+	})
+
+	It("errors on invalid payloads", func() {
+		_, err := Validate([]byte(`[]`))
+		Expect(err).To(HaveOccurred())
+
+		_, err = Validate([]byte(`{"schema_version": "9"}`))
+		Expect(err).To(MatchError(ContainSubstring("unknown schema version '9'")))
+
+		_, err = Validate([]byte(`{"schema_version": 2}`))
+		Expect(err).To(MatchError("schema_version must be a string, got integer"))
+	})
+})
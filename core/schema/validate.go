@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks a payload against the schema of its version, returning
+// the problems found. Payloads without a schema_version are validated as
+// Version1. An error is returned when data isn't a JSON object or its version
+// is unknown.
+func Validate(data []byte) ([]string, error) {
+	var payload map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	version := Version1
+	if v, ok := payload["schema_version"]; ok {
+		str, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("schema_version must be a string, got %s", jsonType(v))
+		}
+		version = str
+	}
+	s, err := Schema(version)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	validate(s, payload, "", &problems)
+	return problems, nil
+}
+
+// validate supports the subset of JSON Schema generated by Schema.
+func validate(s map[string]interface{}, value interface{}, path string, problems *[]string) {
+	report := func(format string, args ...interface{}) {
+		location := path
+		if location == "" {
+			location = "payload"
+		}
+		*problems = append(*problems, location+": "+fmt.Sprintf(format, args...))
+	}
+
+	if expected, ok := s["type"]; ok {
+		types := []string{}
+		switch t := expected.(type) {
+		case string:
+			types = append(types, t)
+		case []interface{}:
+			for _, item := range t {
+				types = append(types, item.(string))
+			}
+		}
+		actual := jsonType(value)
+		if !matchesType(types, actual) {
+			report("expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+	if value == nil {
+		return
+	}
+
+	if enum, ok := s["enum"].([]string); ok {
+		str, _ := value.(string)
+		found := false
+		for _, e := range enum {
+			found = found || e == str
+		}
+		if !found {
+			report("expected one of %s, got '%s'", strings.Join(enum, ", "), str)
+		}
+	}
+	if s["format"] == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, value.(string)); err != nil {
+			report("invalid date-time '%s'", value)
+		}
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case map[string]interface{}:
+		properties, ok := s["properties"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		required, _ := s["required"].([]string)
+		for _, name := range required {
+			if _, ok := v[name]; !ok {
+				report("missing required field '%s'", name)
+			}
+		}
+
+		names := []string{}
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			property, ok := properties[name].(map[string]interface{})
+			if !ok {
+				if s["additionalProperties"] == false {
+					report("unknown field '%s'", name)
+				}
+				continue
+			}
+			validate(property, v[name], fieldPath, problems)
+		}
+	}
+}
+
+func matchesType(types []string, actual string) bool {
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
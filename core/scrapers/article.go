@@ -3,12 +3,16 @@ package scrapers
 import (
 	"bytes"
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
 	"time"
 
 	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	"github.com/fgrehm/brinfo/core/duplicates"
+	"github.com/fgrehm/brinfo/core/hashes"
+	"github.com/fgrehm/brinfo/core/language"
 	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/textstats"
+	"github.com/fgrehm/brinfo/core/urls"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/mitchellh/mapstructure"
@@ -22,36 +26,152 @@ type ArticleScraperConfig struct {
 	Clock      Clock
 	Extractors []xt.Extractor
 	MergeWith  *core.ArticleData
+	// Location is used for interpreting times that don't have an offset, if
+	// not set it is derived from the URL of the page being scraped.
+	Location *time.Location
+	// MinPublishedAt is used for rejecting dates that are older than the
+	// site, DefaultMinPublishedAt is used if not set.
+	MinPublishedAt time.Time
+	// URLDatePatterns are used for inferring the publication date from the
+	// URL, xt.DefaultURLDatePatterns is used if nil.
+	URLDatePatterns []string
+	// URLRules are applied on top of urls.DefaultRules when normalizing the
+	// URL of the article for computing its hash.
+	URLRules []urls.Rule
+	// HashAlgorithm is used for the URL and full text hashes, defaults to
+	// hashes.AlgorithmSHA1.
+	HashAlgorithm string
+	// Classifier labels the pages scraped, a new one is used for each page if
+	// not set. Sharing it between the pages of a source allows it to detect
+	// error pages served on different URLs.
+	Classifier *PageClassifier
 }
 
 type Clock interface {
 	Now() time.Time
 }
 
+type fixedClock struct {
+	now time.Time
+}
+
+// FixedClock returns a Clock that is always set to t, useful for extracting
+// data from pages fetched in the past.
+func FixedClock(t time.Time) Clock {
+	return &fixedClock{t}
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func locationFor(loc *time.Location, url string) *time.Location {
+	if loc != nil {
+		return loc
+	}
+	return dates.LocationForURL(url)
+}
+
 func NewArticleScraper(cfg *ArticleScraperConfig) core.ArticleScraper {
 	return &articleScraper{cfg}
 }
 
 func (s *articleScraper) Run(ctx context.Context, html []byte, url, httpContentType string) (*core.ArticleData, error) {
+	if _, err := hashes.New(s.HashAlgorithm); err != nil {
+		return nil, err
+	}
+
 	data := &core.ArticleData{
 		URL:     url,
 		FoundAt: s.Clock.Now(),
-		Extra: map[string]interface{}{
-			"html": mustGzip(html),
-		},
-	}
-
-	doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(html))
-	if err != nil {
-		return nil, err
 	}
 
 	args := xt.ExtractorArgs{
 		Context:         ctx,
 		URL:             url,
 		HTTPContentType: httpContentType,
-		Root:            doc.Selection,
+		Clock:           s.Clock,
+		Location:        locationFor(s.Location, url),
+	}
+
+	var (
+		candidates dateCandidates
+		err        error
+	)
+	if isPDF(html, httpContentType) {
+		candidates, err = s.extractPDF(data, html, args)
+	} else {
+		candidates, err = s.extractHTML(data, html, args)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	urlDatePatterns := s.URLDatePatterns
+	if urlDatePatterns == nil {
+		urlDatePatterns = xt.DefaultURLDatePatterns
+	}
+	urlDate, err := xt.URLDate(urlDatePatterns...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := urlDate.Extract(args)
+	if err != nil {
+		return nil, err
+	}
+	urlData := &core.ArticleData{}
+	if err = mapstructure.Decode(result, urlData); err != nil {
+		return nil, err
+	}
+	candidates = candidates.addData(xt.DateSourceURL, urlData)
+
+	// TODO: Test this
+	if s.MergeWith != nil {
+		data.CollectValues(s.MergeWith)
+		candidates = candidates.addData(DateSourceMergeWith, s.MergeWith)
+	}
+
+	minPublishedAt := s.MinPublishedAt
+	if minPublishedAt.IsZero() {
+		minPublishedAt = DefaultMinPublishedAt
+	}
+	candidates.choose(data, minPublishedAt)
+
+	if data.URL != "" {
+		s.setCanonicalURL(data)
+	}
+
+	if data.FullText != "" {
+		data.FullTextHash = s.generateHash(hashes.NormalizeText(data.FullText))
+		data.Fingerprint = duplicates.Fingerprint(data.FullText)
+		data.Language, data.LanguageConfidence = language.Detect(data.FullText)
+		stats := textstats.Compute(data.FullText)
+		data.WordCount = stats.Words
+		data.SentenceCount = stats.Sentences
+		data.ReadingMinutes = stats.ReadingMinutes
+	}
+
+	if data.ModifiedAt != nil && data.PublishedAt == nil {
+		data.PublishedAt = data.ModifiedAt
+	}
+
+	return data, nil
+}
+
+// extractHTML runs the extractors over the page, collecting the data they find
+// into data and returning the dates found on the way.
+func (s *articleScraper) extractHTML(data *core.ArticleData, html []byte, args xt.ExtractorArgs) (dateCandidates, error) {
+	data.Extra = map[string]interface{}{
+		"html": mustGzip(html),
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(html))
+	if err != nil {
+		return nil, err
+	}
+	args = args.WithRoot(doc.Selection).WithDateCache()
+
+	candidates := dateCandidates{}
 	for _, extractor := range s.Extractors {
 		result, err := extractor.Extract(args)
 		if err != nil {
@@ -64,32 +184,66 @@ func (s *articleScraper) Run(ctx context.Context, html []byte, url, httpContentT
 			return nil, err
 		}
 		data.CollectValues(extractorData)
+		candidates = candidates.addData(resultDateSource(result), extractorData)
 	}
 
-	// TODO: Test this
-	if s.MergeWith != nil {
-		data.CollectValues(s.MergeWith)
+	classifier := s.Classifier
+	if classifier == nil {
+		classifier = NewPageClassifier()
 	}
+	setPageType(data, classifier.Classify(doc.Selection, args.URL, data.Title, data.FullText))
 
-	if data.URL != "" {
-		data.URLHash = s.generateHash(data.URL)
+	pageCandidates, err := xt.DateCandidates(args)
+	if err != nil {
+		return nil, err
 	}
-
-	if data.FullText != "" {
-		data.FullTextHash = s.generateHash(data.FullTextHash)
+	for _, c := range pageCandidates {
+		candidates = candidates.addCandidate(c)
 	}
+	return candidates, nil
+}
 
-	if data.ModifiedAt != nil && data.PublishedAt == nil {
-		data.PublishedAt = data.ModifiedAt
+// resultDateSource returns the source annotated on the result of an extractor
+// for the dates it found, results that are not annotated are assumed to come
+// from custom extractors.
+func resultDateSource(result xt.ExtractorResult) string {
+	var source interface{}
+	switch r := result.(type) {
+	case map[string]interface{}:
+		source = r["dateSource"]
+	case map[string]xt.ExtractorResult:
+		source = r["dateSource"]
+	}
+	if str, ok := source.(string); ok && str != "" {
+		return str
 	}
+	return DateSourceCustom
+}
 
-	return data, nil
+// setCanonicalURL replaces the canonical URL declared by the page with the
+// normalized URL that identifies the article, which is what the URL hash is
+// computed from. URLs that can't be normalized are hashed as they are.
+func (s *articleScraper) setCanonicalURL(data *core.ArticleData) {
+	canonical, err := urls.Canonical(data.URL, data.CanonicalURL, s.URLRules)
+	if err != nil {
+		data.CanonicalURL = ""
+		data.URLHash = s.generateHash(data.URL)
+		return
+	}
+	key, err := urls.Key(canonical, s.URLRules)
+	if err != nil {
+		key = canonical
+	}
+	data.CanonicalURL = canonical
+	data.URLHash = s.generateHash(key)
 }
 
+// generateHash hashes text with the algorithm configured, which is validated
+// at the start of Run.
 func (s *articleScraper) generateHash(text string) string {
-	algorithm := sha1.New()
-	if _, err := algorithm.Write([]byte(text)); err != nil {
+	hash, err := hashes.Hash(s.HashAlgorithm, text)
+	if err != nil {
 		panic(err)
 	}
-	return hex.EncodeToString(algorithm.Sum(nil))
+	return hash
 }
@@ -0,0 +1,151 @@
+package scrapers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var whitespaceRegexp = regexp.MustCompile(`\s*`)
+
+type articleListScraper struct {
+	extractor xt.Extractor
+	clock     Clock
+	location  *time.Location
+}
+
+type ArticleListScraperConfig struct {
+	Clock Clock
+	// Location is used for interpreting times that don't have an offset, if
+	// not set it is derived from the URL of the page being scraped.
+	Location             *time.Location
+	LinkContainer        string
+	URLExtractor         string
+	PublishedAtExtractor string
+	ImageURLExtractor    string
+}
+
+func NewArticleListScraper(cfg *ArticleListScraperConfig) (core.ArticleListScraper, error) {
+	if cfg.LinkContainer == "" {
+		return nil, errors.New("no link container")
+	}
+	if cfg.URLExtractor == "" {
+		return nil, errors.New("no url extractor")
+	}
+
+	extractors := map[string]xt.Extractor{}
+
+	e, err := xt.FromString(cfg.URLExtractor)
+	if err != nil {
+		return nil, err
+	}
+	extractors["url"] = e
+
+	if cfg.PublishedAtExtractor != "" {
+		e, err = xt.FromString(cfg.PublishedAtExtractor)
+		if err != nil {
+			return nil, err
+		}
+		extractors["published_at"] = e
+	}
+
+	if cfg.ImageURLExtractor != "" {
+		e, err = xt.FromString(cfg.ImageURLExtractor)
+		if err != nil {
+			return nil, err
+		}
+		extractors["image_url"] = e
+	}
+
+	return &articleListScraper{
+		extractor: xt.StructuredList(cfg.LinkContainer, extractors),
+		clock:     cfg.Clock,
+		location:  cfg.Location,
+	}, nil
+}
+
+func (s *articleListScraper) Run(ctx context.Context, html []byte, url, httpContentType string) ([]*core.ArticleLink, error) {
+	parsedURL, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(html))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.extractor.Extract(xt.ExtractorArgs{
+		Context:         ctx,
+		URL:             url,
+		HTTPContentType: httpContentType,
+		Root:            doc.Selection,
+		Clock:           s.clock,
+		Location:        locationFor(s.location, url),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := data.([]map[string]xt.ExtractorResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result from the links extractor: %T", data)
+	}
+
+	ret := []*core.ArticleLink{}
+	for _, res := range list {
+		link := &core.ArticleLink{}
+
+		if res["published_at"] != nil {
+			pubAt, ok := res["published_at"].(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("expected a time from the published_at extractor, got %T", res["published_at"])
+			}
+			link.PublishedAt = &pubAt
+			link.PublishedAtPrecision, _ = res[xt.PrecisionField("published_at")].(dates.Precision)
+		}
+		if res["image_url"] != nil {
+			imageURL, ok := res["image_url"].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string from the image_url extractor, got %T", res["image_url"])
+			}
+			imageURL, err := fixRelativeURL(parsedURL, imageURL)
+			if err != nil {
+				return nil, err
+			}
+			link.ImageURL = &imageURL
+		}
+		linkURL, ok := res["url"].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string from the url extractor, got %T", res["url"])
+		}
+		link.URL, err = fixRelativeURL(parsedURL, linkURL)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, link)
+	}
+
+	return ret, nil
+}
+
+// fixRelativeURL resolves url against the URL of the listing it was found on.
+func fixRelativeURL(base *neturl.URL, url string) (string, error) {
+	url = whitespaceRegexp.ReplaceAllString(url, "")
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
@@ -0,0 +1,98 @@
+package scrapers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ArticleListScraper", func() {
+	var ctx context.Context
+
+	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("extracts links and metadata from the provided HTML", func() {
+		s, err := NewArticleListScraper(&ArticleListScraperConfig{
+			LinkContainer:        "ul li",
+			URLExtractor:         "a[href] | href",
+			PublishedAtExtractor: "time | text?::time",
+			ImageURLExtractor:    "img | src?",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		body := `<ul>
+			<li><a href="/first">First</a><img src="/img.png"></li>
+			<li><a href="https://other.com/second">Second</a><time>08/06/2020 23:11</time></li>
+		</ul>`
+		data, err := s.Run(ctx, []byte(body), "http://example.com/list", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		sampleImg := "http://example.com/img.png"
+		sampleDate := time.Date(2020, 6, 8, 23, 11, 0, 0, brLoc)
+		Expect(data).To(Equal([]*ArticleLink{
+			{URL: "http://example.com/first", ImageURL: &sampleImg},
+			{URL: "https://other.com/second", PublishedAt: &sampleDate, PublishedAtPrecision: dates.PrecisionMinute},
+		}))
+	})
+
+	It("resolves links relative to the path of the listing", func() {
+		s, err := NewArticleListScraper(&ArticleListScraperConfig{
+			LinkContainer: "ul li",
+			URLExtractor:  "a[href] | href",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		body := `<ul>
+			<li><a href="noticia.php?id=1">First</a></li>
+			<li><a href="../arquivo/second">Second</a></li>
+			<li><a href="//cdn.example.com/third">Third</a></li>
+		</ul>`
+		data, err := s.Run(ctx, []byte(body), "http://example.com/noticias/lista.php?pagina=2", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]*ArticleLink{
+			{URL: "http://example.com/noticias/noticia.php?id=1"},
+			{URL: "http://example.com/arquivo/second"},
+			{URL: "http://cdn.example.com/third"},
+		}))
+	})
+
+	It("errors on missing extractors", func() {
+		_, err := NewArticleListScraper(&ArticleListScraperConfig{URLExtractor: "a[href] | href"})
+		Expect(err).To(MatchError("no link container"))
+
+		_, err = NewArticleListScraper(&ArticleListScraperConfig{LinkContainer: "ul li"})
+		Expect(err).To(MatchError("no url extractor"))
+	})
+
+	It("errors on unexpected results", func() {
+		s, err := NewArticleListScraper(&ArticleListScraperConfig{
+			LinkContainer:        "ul li",
+			URLExtractor:         "a[href] | href",
+			PublishedAtExtractor: "time | text?",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = s.Run(ctx, []byte(`<ul><li><a href="/first">First</a><time>ontem</time></li></ul>`), "http://example.com/list", "")
+		Expect(err).To(MatchError("expected a time from the published_at extractor, got string"))
+	})
+
+	It("errors on invalid extractors", func() {
+		_, err := NewArticleListScraper(&ArticleListScraperConfig{
+			LinkContainer: "ul li",
+			URLExtractor:  "a[href]",
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,7 @@ import (
 
 	. "github.com/fgrehm/brinfo/core"
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+	"github.com/fgrehm/brinfo/core/urls"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -39,14 +40,99 @@ var _ = Describe("ArticleScraper", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(data).To(Equal(&ArticleData{
 			Extra: map[string]interface{}{
-				"html": mustGzip([]byte(body)),
+				"html":              mustGzip([]byte(body)),
+				"page_type":         PageTypeOther,
+				"page_type_reasons": []string{"home page"},
 			},
-			URL:     "http://example.com",
-			URLHash: "89dce6a446a69d6b9bdc01ac75251e4c322bcdff",
-			FoundAt: now,
+			URL:          "http://example.com",
+			URLHash:      "0caaf24ab1a0c33440c06afe99df986365b0781f",
+			CanonicalURL: "http://example.com/",
+			FoundAt:      now,
 		}))
 	})
 
+	It("hashes the canonical URL of the article", func() {
+		cfg.Extractors = []Extractor{&fakeExtractor{map[string]interface{}{
+			"canonicalURL": "/noticias/vacina/",
+		}}}
+
+		body := `<html><body><p>Don't care</p></body><html>`
+		data, err := s.Run(ctx, []byte(body), "https://www.es.gov.br/noticias/vacina?utm_source=facebook", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.URL).To(Equal("https://www.es.gov.br/noticias/vacina?utm_source=facebook"))
+		Expect(data.CanonicalURL).To(Equal("https://www.es.gov.br/noticias/vacina/"))
+
+		other, err := s.Run(ctx, []byte(body), "http://es.gov.br/noticias/vacina", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other.URLHash).To(Equal(data.URLHash))
+	})
+
+	It("classifies the page with the classifier of the source", func() {
+		cfg.Extractors = []Extractor{&fakeExtractor{map[string]interface{}{
+			"title":    "Página inicial",
+			"fullText": noteText,
+		}}}
+		cfg.Classifier = NewPageClassifier()
+
+		body := `<html><body><p>Don't care</p></body><html>`
+		data, err := s.Run(ctx, []byte(body), "https://www.es.gov.br/noticias/a", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Extra).To(HaveKeyWithValue("page_type", PageTypeArticle))
+		Expect(data.Extra).NotTo(HaveKey("page_type_reasons"))
+
+		data, err = s.Run(ctx, []byte(body), "https://www.es.gov.br/noticias/b", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Extra).To(HaveKeyWithValue("page_type", PageTypeError))
+		Expect(data.Extra).To(HaveKeyWithValue("page_type_reasons", []string{"same title and text as https://www.es.gov.br/noticias/a"}))
+	})
+
+	It("applies the URL rules of the source", func() {
+		cfg.Extractors = []Extractor{&fakeExtractor{}}
+		cfg.URLRules = []urls.Rule{{Host: "ac.gov.br", KeepParams: []string{"id"}}}
+
+		body := `<html><body><p>Don't care</p></body><html>`
+		data, err := s.Run(ctx, []byte(body), "https://agencia.ac.gov.br/noticia.php?id=12&sessao=abc", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.CanonicalURL).To(Equal("https://agencia.ac.gov.br/noticia.php?id=12"))
+	})
+
+	Context("full text hash", func() {
+		body := `<html><body><p>Don't care</p></body><html>`
+		hashText := func(text string) string {
+			cfg.Extractors = []Extractor{&fakeExtractor{map[string]interface{}{
+				"fullText": text,
+			}}}
+			data, err := s.Run(ctx, []byte(body), "http://example.com", "")
+			Expect(err).NotTo(HaveOccurred())
+			return data.FullTextHash
+		}
+
+		It("differs for different texts", func() {
+			Expect(hashText("Governo anuncia medidas")).NotTo(Equal(hashText("Governo anuncia novas medidas")))
+		})
+
+		It("is stable for cosmetic changes", func() {
+			hash := hashText("Governo anuncia medidas\nO governo anunciou “novas” medidas.")
+			Expect(hashText("  Governo   anuncia medidas\r\n\n\u00a0O governo anunciou \"novas\" medidas. ")).To(Equal(hash))
+			Expect(hashText("Compartilhe: Facebook Twitter\nGoverno anuncia medidas\nO governo anunciou “novas” medidas.\nImprimir")).To(Equal(hash))
+		})
+
+		It("uses the algorithm configured", func() {
+			cfg.HashAlgorithm = "sha256"
+			Expect(hashText("Governo anuncia medidas")).To(HaveLen(64))
+
+			data, err := s.Run(ctx, []byte(body), "http://example.com", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.URLHash).To(HaveLen(64))
+		})
+
+		It("fails for unknown algorithms", func() {
+			cfg.HashAlgorithm = "md5"
+			_, err := s.Run(ctx, []byte(body), "http://example.com", "")
+			Expect(err).To(MatchError("unknown hash algorithm 'md5'"))
+		})
+	})
+
 	It("maps extractor result into an ArticleData", func() {
 		cfg.Extractors = []Extractor{
 			&fakeExtractor{map[string]interface{}{
@@ -60,13 +146,16 @@ var _ = Describe("ArticleScraper", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(data).To(Equal(&ArticleData{
 			Extra: map[string]interface{}{
-				"html": mustGzip([]byte(body)),
+				"html":              mustGzip([]byte(body)),
+				"page_type":         PageTypeOther,
+				"page_type_reasons": []string{"home page"},
 			},
-			URL:     "http://example.com",
-			URLHash: "89dce6a446a69d6b9bdc01ac75251e4c322bcdff",
-			Title:   "Finally a cure for COVID19!",
-			Excerpt: "A summary of how it attacks the virus",
-			FoundAt: now,
+			URL:          "http://example.com",
+			URLHash:      "0caaf24ab1a0c33440c06afe99df986365b0781f",
+			CanonicalURL: "http://example.com/",
+			Title:        "Finally a cure for COVID19!",
+			Excerpt:      "A summary of how it attacks the virus",
+			FoundAt:      now,
 		}))
 	})
 
@@ -89,34 +178,72 @@ var _ = Describe("ArticleScraper", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(data).To(Equal(&ArticleData{
 			Extra: map[string]interface{}{
-				"html": mustGzip([]byte(body)),
+				"html":              mustGzip([]byte(body)),
+				"page_type":         PageTypeOther,
+				"page_type_reasons": []string{"home page"},
 			},
-			URL:     "http://example.com",
-			URLHash: "89dce6a446a69d6b9bdc01ac75251e4c322bcdff",
-			Title:   "Title",
-			Excerpt: "Random stuff",
-			FoundAt: now,
+			URL:          "http://example.com",
+			URLHash:      "0caaf24ab1a0c33440c06afe99df986365b0781f",
+			CanonicalURL: "http://example.com/",
+			Title:        "Title",
+			Excerpt:      "Random stuff",
+			FoundAt:      now,
 		}))
 	})
 
+	It("interprets times without offset in the timezone of the source", func() {
+		cfg.Extractors = []Extractor{
+			Structured("body", map[string]Extractor{
+				"publishedAt": TimeText("time"),
+			}),
+		}
+		body := `<html><body><time>21/02/2020 16h50</time></body><html>`
+
+		data, err := s.Run(ctx, []byte(body), "https://agencia.ac.gov.br/noticia", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.UTC()).To(Equal(time.Date(2020, 2, 21, 21, 50, 0, 0, time.UTC)))
+
+		data, err = s.Run(ctx, []byte(body), "https://www.gov.br/noticia", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.UTC()).To(Equal(time.Date(2020, 2, 21, 19, 50, 0, 0, time.UTC)))
+
+		cfg.Location, err = time.LoadLocation("America/Manaus")
+		Expect(err).NotTo(HaveOccurred())
+		data, err = s.Run(ctx, []byte(body), "https://agencia.ac.gov.br/noticia", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.UTC()).To(Equal(time.Date(2020, 2, 21, 20, 50, 0, 0, time.UTC)))
+	})
+
 	It("is capable of extracting all of necessary ArticleData attributes", func() {
 		pubDate := time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)
 		modDate := time.Date(2020, 6, 15, 19, 59, 0, 0, brLoc)
 		body := `<html><body><p>Don't care</p></body><html>`
 		expectedData := &ArticleData{
 			Extra: map[string]interface{}{
-				"a":    "b",
-				"html": mustGzip([]byte(body)),
+				"a":                 "b",
+				"html":              mustGzip([]byte(body)),
+				"page_type":         PageTypeOther,
+				"page_type_reasons": []string{"home page"},
 			},
 			URL:          "http://example.com",
-			URLHash:      "89dce6a446a69d6b9bdc01ac75251e4c322bcdff",
+			URLHash:      "0caaf24ab1a0c33440c06afe99df986365b0781f",
+			CanonicalURL: "http://example.com/",
 			Title:        "Article title",
 			FullText:     "Lots of text here",
-			FullTextHash: "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			FullTextHash: "e2ad1b1a7b4fb60a1fcb78cbd87965232d132ffa",
+			Fingerprint:  "05005b62c4405001",
 			ImageURL:     "https://image.com",
 			PublishedAt:  &pubDate,
 			ModifiedAt:   &modDate,
 			FoundAt:      now,
+
+			PublishedAtConfidence: 0.95,
+			ModifiedAtConfidence:  0.95,
+
+			Language:       "und",
+			WordCount:      4,
+			SentenceCount:  1,
+			ReadingMinutes: 1,
 		}
 
 		cfg.Extractors = []Extractor{
@@ -0,0 +1,141 @@
+package scrapers
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/content"
+	"github.com/fgrehm/brinfo/core/hashes"
+	"github.com/fgrehm/brinfo/core/urls"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// minArticleTextLength is the number of characters below which a page
+	// is not considered to have an article, government notes are rarely
+	// shorter than a couple of paragraphs.
+	minArticleTextLength = 280
+	// minListingLinks and minListingLinkDensity are the number of links and
+	// the share of the text within them on the main content of listings.
+	minListingLinks       = 8
+	minListingLinkDensity = 0.5
+)
+
+var (
+	notFoundRegexp    = regexp.MustCompile(`(?i)^\s*(o?ops!?\s*)?(erros? 404|error 404|404|p[áa]gina n[ãa]o (encontrada|existe)|conte[úu]do n[ãa]o (encontrado|dispon[íi]vel)|not found|page not found|p[áa]gina indispon[íi]vel)\b`)
+	loginRegexp       = regexp.MustCompile(`(?i)\b(login|log in|sign in|fa[çc]a (o )?login|acesso (restrito|negado)|[áa]rea restrita|autentica[çc][ãa]o)\b`)
+	listingPathRegexp = regexp.MustCompile(`(?i)/(page|pagina|category|categoria|categorias|tag|tags|editoria|editorias|secao|arquivo|archive)(/|$)`)
+)
+
+// PageClassification is the type assigned to a page along with what led to
+// it, articles have no reasons.
+type PageClassification struct {
+	Type    string
+	Reasons []string
+}
+
+// PageClassifier labels fetched pages as articles, listings, error pages or
+// other kinds of pages based on their titles, the length of their text and
+// how much of it is links. It remembers the pages seen from each host, so
+// that a classifier shared by the scrapers of a source catches error pages
+// served with a 200 status on different URLs.
+type PageClassifier struct {
+	mu        sync.Mutex
+	templates map[string]string
+}
+
+func NewPageClassifier() *PageClassifier {
+	return &PageClassifier{templates: map[string]string{}}
+}
+
+// Classify labels the page found at pageURL, which has root as its document
+// and the title and full text extracted from it.
+func (c *PageClassifier) Classify(root *goquery.Selection, pageURL, title, fullText string) *PageClassification {
+	// Error pages are only recognized by their titles when they have little
+	// text, so that articles with titles like "404 professores são
+	// contratados" are not mistaken for them.
+	headings := []string{title, strings.TrimSpace(root.Find("h1").First().Text())}
+	textLength := utf8.RuneCountInString(strings.TrimSpace(fullText))
+	if textLength < minArticleTextLength {
+		for _, h := range headings {
+			if notFoundRegexp.MatchString(h) {
+				return &PageClassification{Type: core.PageTypeError, Reasons: []string{"title of an error page: " + h}}
+			}
+		}
+		if root.Find(`input[type="password"]`).Length() > 0 {
+			return &PageClassification{Type: core.PageTypeError, Reasons: []string{"login form"}}
+		}
+		for _, h := range headings {
+			if loginRegexp.MatchString(h) {
+				return &PageClassification{Type: core.PageTypeError, Reasons: []string{"title of a login page: " + h}}
+			}
+		}
+	}
+
+	if first := c.remember(pageURL, title, fullText); first != "" {
+		return &PageClassification{Type: core.PageTypeError, Reasons: []string{"same title and text as " + first}}
+	}
+
+	main := content.MainContent(root)
+	links := main.Find("a[href]").Length()
+	if links >= minListingLinks {
+		if density := content.LinkDensity(main); density >= minListingLinkDensity {
+			return &PageClassification{Type: core.PageTypeListing, Reasons: []string{"most of the content is links"}}
+		}
+		if u, err := url.Parse(pageURL); err == nil && listingPathRegexp.MatchString(u.Path) {
+			return &PageClassification{Type: core.PageTypeListing, Reasons: []string{"URL of a listing: " + u.Path}}
+		}
+	}
+
+	if u, err := url.Parse(pageURL); err == nil && strings.Trim(u.Path, "/") == "" && u.RawQuery == "" {
+		return &PageClassification{Type: core.PageTypeOther, Reasons: []string{"home page"}}
+	}
+	if textLength < minArticleTextLength {
+		return &PageClassification{Type: core.PageTypeOther, Reasons: []string{"text is too short"}}
+	}
+	return &PageClassification{Type: core.PageTypeArticle}
+}
+
+// remember records the title and text of the page, returning the URL of a
+// different page from the same host that was seen with them.
+func (c *PageClassifier) remember(pageURL, title, fullText string) string {
+	key, err := urls.Key(pageURL, nil)
+	if err != nil || (title == "" && fullText == "") {
+		return ""
+	}
+	host := key
+	if i := strings.IndexAny(key, "/?"); i >= 0 {
+		host = key[:i]
+	}
+	template, err := hashes.Hash(hashes.AlgorithmSHA1, title+"\n"+hashes.NormalizeText(fullText))
+	if err != nil {
+		panic(err)
+	}
+	template = host + " " + template
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	first, ok := c.templates[template]
+	if !ok {
+		c.templates[template] = pageURL
+		return ""
+	}
+	if firstKey, _ := urls.Key(first, nil); firstKey == key {
+		return ""
+	}
+	return first
+}
+
+// setPageType records the classification of the page on the extra data of
+// the article.
+func setPageType(data *core.ArticleData, classification *PageClassification) {
+	data.Extra["page_type"] = classification.Type
+	if len(classification.Reasons) > 0 {
+		data.Extra["page_type_reasons"] = classification.Reasons
+	}
+}
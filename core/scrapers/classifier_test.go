@@ -0,0 +1,124 @@
+package scrapers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+
+	"github.com/PuerkitoBio/goquery"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const noteText = `A Secretaria da Educação abriu nesta segunda-feira as inscrições para o processo seletivo de professores temporários da rede estadual. Os candidatos devem se inscrever pela internet até o dia 30 e apresentar os documentos exigidos no edital. As vagas são para todas as regiões do estado e a classificação levará em conta a formação e o tempo de experiência em sala de aula.`
+
+func classifyPage(c *PageClassifier, pageURL, body, title, text string) *PageClassification {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewBufferString(body))
+	Expect(err).NotTo(HaveOccurred())
+	return c.Classify(doc.Selection, pageURL, title, text)
+}
+
+func articlePage(title, text string) string {
+	return fmt.Sprintf(`<html><body><nav><a href="/">Início</a> <a href="/noticias">Notícias</a></nav><h1>%s</h1><div class="entry-content"><p>%s</p></div></body></html>`, title, text)
+}
+
+func listingPage() string {
+	items := []string{}
+	for i := 0; i < 10; i++ {
+		items = append(items, fmt.Sprintf(`<li><a href="/noticias/nota-%d">Secretaria divulga a nota número %d sobre a educação</a> 12/05/2020</li>`, i, i))
+	}
+	return `<html><body><h1>Notícias</h1><ul class="noticias">` + strings.Join(items, "") + `</ul></body></html>`
+}
+
+var _ = Describe("PageClassifier", func() {
+	var c *PageClassifier
+
+	BeforeEach(func() {
+		c = NewPageClassifier()
+	})
+
+	It("classifies articles", func() {
+		title := "Inscrições abertas para professores temporários"
+		result := classifyPage(c, "https://sedu.es.gov.br/noticias/inscricoes", articlePage(title, noteText), title, noteText)
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeArticle}))
+	})
+
+	It("classifies pages with the titles of error pages", func() {
+		for _, title := range []string{"Página não encontrada", "Erro 404 - Governo do Estado", "Oops! Page not found", "Conteúdo não disponível"} {
+			result := classifyPage(c, "https://www.es.gov.br/noticias/"+title, articlePage(title, "Verifique o endereço digitado."), title, "Verifique o endereço digitado.")
+			Expect(result.Type).To(Equal(core.PageTypeError), title)
+		}
+
+		result := classifyPage(c, "https://www.es.gov.br/noticias/x", articlePage("Página não encontrada", ""), "Governo do Estado", "")
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeError, Reasons: []string{"title of an error page: Página não encontrada"}}))
+	})
+
+	It("doesn't mistake articles mentioning errors for error pages", func() {
+		titles := []string{
+			"Governo contrata 404 professores temporários",
+			"Rodovia BR-404 recebe obras",
+			"Polícia apreende arma com a mensagem not found",
+			"404 professores são contratados pela Secretaria da Educação",
+		}
+		for _, title := range titles {
+			result := classifyPage(c, "https://www.es.gov.br/noticias/"+title, articlePage(title, noteText), title, noteText)
+			Expect(result.Type).To(Equal(core.PageTypeArticle), title)
+		}
+
+		title := "Governo contrata 404 professores temporários"
+		result := classifyPage(c, "https://www.es.gov.br/noticias/curta", articlePage(title, "Nota curta."), title, "Nota curta.")
+		Expect(result.Type).To(Equal(core.PageTypeOther))
+	})
+
+	It("classifies login walls", func() {
+		body := `<html><body><h1>Portal</h1><form><input name="user"><input type="password" name="pass"></form></body></html>`
+		result := classifyPage(c, "https://intranet.es.gov.br/noticia", body, "Portal", "Usuário Senha")
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeError, Reasons: []string{"login form"}}))
+
+		result = classifyPage(c, "https://intranet.es.gov.br/noticia", articlePage("Acesso restrito", "Entre com seu usuário."), "Acesso restrito", "Entre com seu usuário.")
+		Expect(result.Type).To(Equal(core.PageTypeError))
+	})
+
+	It("doesn't mistake articles about logins for login walls", func() {
+		title := "Servidores devem fazer login no novo sistema de ponto"
+		result := classifyPage(c, "https://www.es.gov.br/noticias/ponto", articlePage(title, noteText), title, noteText)
+		Expect(result.Type).To(Equal(core.PageTypeArticle))
+	})
+
+	It("classifies listings", func() {
+		result := classifyPage(c, "https://www.es.gov.br/noticias", listingPage(), "Notícias", noteText)
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeListing, Reasons: []string{"most of the content is links"}}))
+	})
+
+	It("classifies listings by their URLs", func() {
+		items := []string{}
+		for i := 0; i < 8; i++ {
+			items = append(items, fmt.Sprintf(`<p><a href="/noticias/nota-%d">Nota %d</a> %s</p>`, i, i, noteText[:120]))
+		}
+		body := `<html><body><div class="entry-content">` + strings.Join(items, "") + `</div></body></html>`
+		result := classifyPage(c, "https://www.es.gov.br/category/educacao/page/2", body, "Educação", noteText)
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeListing, Reasons: []string{"URL of a listing: /category/educacao/page/2"}}))
+	})
+
+	It("classifies home pages and pages with little text as other", func() {
+		result := classifyPage(c, "https://www.es.gov.br/", articlePage("Governo do Estado", noteText), "Governo do Estado", noteText)
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeOther, Reasons: []string{"home page"}}))
+
+		result = classifyPage(c, "https://www.es.gov.br/agenda", articlePage("Agenda", "Sem compromissos hoje."), "Agenda", "Sem compromissos hoje.")
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeOther, Reasons: []string{"text is too short"}}))
+	})
+
+	It("classifies pages with the same content on different URLs of a source as errors", func() {
+		title := "Governo do Estado"
+		body := articlePage(title, noteText)
+		Expect(classifyPage(c, "https://www.es.gov.br/noticias/a", body, title, noteText).Type).To(Equal(core.PageTypeArticle))
+		Expect(classifyPage(c, "https://es.gov.br/noticias/a?utm_source=facebook", body, title, noteText).Type).To(Equal(core.PageTypeArticle))
+		Expect(classifyPage(c, "https://www.sedu.es.gov.br/noticias/b", body, title, noteText).Type).To(Equal(core.PageTypeArticle))
+
+		result := classifyPage(c, "https://www.es.gov.br/noticias/b", body, title, noteText)
+		Expect(result).To(Equal(&PageClassification{Type: core.PageTypeError, Reasons: []string{"same title and text as https://www.es.gov.br/noticias/a"}}))
+	})
+})
@@ -0,0 +1,223 @@
+package scrapers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+)
+
+// Names of date sources that are not part of the page itself.
+const (
+	DateSourceCustom    = "custom"
+	DateSourceMergeWith = "merge_with"
+)
+
+// DefaultMinPublishedAt is used for rejecting dates when
+// ArticleScraperConfig.MinPublishedAt is not set.
+var DefaultMinPublishedAt = time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DateSourceConfidence is how much dates found on each source are trusted
+// before checking whether they agree with other sources or look suspicious.
+var DateSourceConfidence = map[string]float64{
+	DateSourceCustom:         0.95,
+	DateSourceMergeWith:      0.92,
+	xt.DateSourceOpenGraph:   0.9,
+	xt.DateSourceRNews:       0.85,
+	xt.DateSourceTime:        0.8,
+	DateSourcePDF:            0.7,
+	xt.DateSourceLabeledText: 0.6,
+	xt.DateSourceURL:         0.3,
+}
+
+// overridingSources are provided by whoever runs the scraper for a source, so
+// their dates win over the ones found on the page regardless of how many of
+// its sources agree, earlier ones first.
+var overridingSources = []string{DateSourceCustom, DateSourceMergeWith}
+
+const (
+	unknownSourceConfidence = 0.5
+	agreementBonus          = 0.1
+	suspiciousPenalty       = 0.5
+	// Times in the future are tolerated up to this point to account for
+	// wrong timezones.
+	futureTolerance = 12 * time.Hour
+)
+
+// ScoredDate is a value found for one of the dates of an article along
+// with the sources it was found on.
+type ScoredDate struct {
+	Field      string          `json:"field"`
+	Value      time.Time       `json:"value"`
+	Sources    []string        `json:"sources"`
+	Confidence float64         `json:"confidence"`
+	Precision  dates.Precision `json:"precision,omitempty"`
+	Rejected   string          `json:"rejected,omitempty"`
+}
+
+type dateCandidates []*ScoredDate
+
+func (c dateCandidates) add(field, source string, value *time.Time, precision dates.Precision) dateCandidates {
+	if value == nil || value.IsZero() {
+		return c
+	}
+	for _, candidate := range c {
+		if candidate.Field == field && candidate.Value.Equal(*value) {
+			if candidate.Precision == "" || precision.MorePreciseThan(candidate.Precision) {
+				candidate.Precision = precision
+			}
+			for _, s := range candidate.Sources {
+				if s == source {
+					return c
+				}
+			}
+			candidate.Sources = append(candidate.Sources, source)
+			return c
+		}
+	}
+	return append(c, &ScoredDate{Field: field, Value: *value, Sources: []string{source}, Precision: precision})
+}
+
+func (c dateCandidates) addCandidate(candidate *xt.DateCandidate) dateCandidates {
+	return c.add("published_at", candidate.Source, candidate.PublishedAt, candidate.PublishedAtPrecision).
+		add("updated_at", candidate.Source, candidate.ModifiedAt, candidate.ModifiedAtPrecision)
+}
+
+func (c dateCandidates) addData(source string, data *core.ArticleData) dateCandidates {
+	return c.add("published_at", source, data.PublishedAt, data.PublishedAtPrecision).
+		add("updated_at", source, data.ModifiedAt, data.ModifiedAtPrecision)
+}
+
+// choose scores the candidates and sets the most trustworthy dates on data,
+// dropping dates that are before minPublishedAt, after data.FoundAt or
+// modifications that happened before the publication.
+func (c dateCandidates) choose(data *core.ArticleData, minPublishedAt time.Time) {
+	data.PublishedAt, data.PublishedAtConfidence, data.PublishedAtPrecision = nil, 0, ""
+	data.ModifiedAt, data.ModifiedAtConfidence, data.ModifiedAtPrecision = nil, 0, ""
+
+	for _, candidate := range c {
+		candidate.score(data.FoundAt, minPublishedAt)
+	}
+	sort.SliceStable(c, func(i, j int) bool {
+		if ri, rj := c[i].rank(), c[j].rank(); ri != rj {
+			return ri > rj
+		}
+		return c[i].Confidence > c[j].Confidence
+	})
+
+	if best, confidence := c.best("published_at"); best != nil {
+		value := best.Value
+		data.PublishedAt, data.PublishedAtConfidence, data.PublishedAtPrecision = &value, confidence, best.Precision
+	}
+
+	for _, candidate := range c {
+		if candidate.Field == "updated_at" && candidate.Rejected == "" &&
+			data.PublishedAt != nil && candidate.Value.Before(*data.PublishedAt) {
+			candidate.Rejected = "before published_at"
+		}
+	}
+	if best, confidence := c.best("updated_at"); best != nil {
+		value := best.Value
+		data.ModifiedAt, data.ModifiedAtConfidence, data.ModifiedAtPrecision = &value, confidence, best.Precision
+	}
+}
+
+// best returns the candidate with the highest confidence for field, unless
+// there is a more precise candidate that agrees with it, like a listing date
+// without time and the date with time found on the article page.
+func (c dateCandidates) best(field string) (*ScoredDate, float64) {
+	var best *ScoredDate
+	for _, candidate := range c {
+		if candidate.Field != field || candidate.Rejected != "" {
+			continue
+		}
+		if best == nil {
+			best = candidate
+			continue
+		}
+
+		precision := best.effectivePrecision()
+		if candidate.effectivePrecision().MorePreciseThan(precision) &&
+			dates.Truncate(candidate.Value.In(best.Value.Location()), precision).Equal(best.Value) {
+			return candidate, best.Confidence
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+	return best, best.Confidence
+}
+
+// rank orders candidates from overriding sources before the ones found on
+// the page.
+func (c *ScoredDate) rank() int {
+	for i, overriding := range overridingSources {
+		for _, source := range c.Sources {
+			if source == overriding {
+				return len(overridingSources) - i
+			}
+		}
+	}
+	return 0
+}
+
+func (c *ScoredDate) effectivePrecision() dates.Precision {
+	if c.Precision != "" {
+		return c.Precision
+	}
+	return dates.PrecisionOf(c.Value)
+}
+
+func (c *ScoredDate) score(foundAt, minPublishedAt time.Time) {
+	c.Confidence = 0
+	if c.Value.Before(minPublishedAt) {
+		c.Rejected = fmt.Sprintf("before %s", minPublishedAt.Format("2006-01-02"))
+		return
+	}
+	if !foundAt.IsZero() && c.Value.Sub(foundAt) >= futureTolerance {
+		c.Rejected = "after found_at"
+		return
+	}
+
+	for _, source := range c.Sources {
+		confidence, ok := DateSourceConfidence[source]
+		if !ok {
+			confidence = unknownSourceConfidence
+		}
+		if confidence > c.Confidence {
+			c.Confidence = confidence
+		}
+	}
+	c.Confidence += agreementBonus * float64(len(c.Sources)-1)
+
+	if !c.effectivePrecision().MorePreciseThan(dates.PrecisionDay) && !c.hasSource(xt.DateSourceURL) {
+		// Copyright notices end up as the first day of the year and sidebar
+		// widgets usually display the current date, URLs are left alone since
+		// they only have the year and month most of the time
+		if c.Value.Month() == time.January && c.Value.Day() == 1 {
+			c.Confidence *= suspiciousPenalty
+		} else if !foundAt.IsZero() && sameDay(c.Value, foundAt.In(c.Value.Location())) {
+			c.Confidence *= suspiciousPenalty
+		}
+	}
+
+	if c.Confidence > 1 {
+		c.Confidence = 1
+	}
+}
+
+func (c *ScoredDate) hasSource(source string) bool {
+	for _, s := range c.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
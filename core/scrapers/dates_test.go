@@ -0,0 +1,168 @@
+package scrapers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/dates"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ArticleScraper dates", func() {
+	var (
+		cfg *ArticleScraperConfig
+		ctx context.Context
+		now time.Time
+	)
+
+	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2020, 6, 20, 15, 30, 0, 0, brLoc)
+		cfg = &ArticleScraperConfig{Clock: fakeClock{now}, Extractors: []Extractor{BasicArticle()}}
+		ctx = context.Background()
+	})
+
+	run := func(body string) *ArticleData {
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(body), "http://example.com", "")
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("boosts the confidence of dates found on multiple sources", func() {
+		data := run(`<html><head>
+			<meta property="article:published_time" content="2020-06-15 19:56">
+		</head><body>
+			<article><time pubdate datetime="2020-06-15 19:56">15/06/2020</time></article>
+		</body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)))
+		Expect(data.PublishedAtConfidence).To(Equal(1.0))
+	})
+
+	It("rejects dates after the page was found", func() {
+		data := run(`<html><head>
+			<meta property="article:published_time" content="2021-01-01 10:00">
+		</head><body>
+			<p>Publicado: 15/06/2020 19h56</p>
+		</body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)))
+		Expect(data.PublishedAtConfidence).To(Equal(0.6))
+	})
+
+	It("rejects dates before the site existed", func() {
+		cfg.MinPublishedAt = time.Date(2019, 1, 1, 0, 0, 0, 0, brLoc)
+		data := run(`<html><head>
+			<meta property="article:published_time" content="2010-06-15 10:00">
+		</head><body></body></html>`)
+		Expect(data.PublishedAt).To(BeNil())
+	})
+
+	It("rejects modifications that happened before the publication", func() {
+		data := run(`<html><head>
+			<meta property="article:published_time" content="2020-06-15 19:56">
+			<meta property="article:modified_time" content="2020-06-14 10:00">
+		</head><body></body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)))
+		Expect(data.ModifiedAt).To(BeNil())
+	})
+
+	It("penalizes dates that look like the current date or a year", func() {
+		data := run(`<html><body>
+			<article><time pubdate datetime="2020-01-01">01/01/2020</time></article>
+			<p>Publicado em 12/05/2020 10h30</p>
+		</body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+		Expect(data.PublishedAtConfidence).To(Equal(0.6))
+
+		data = run(`<html><body>
+			<article><time pubdate datetime="2020-06-20">20/06/2020</time></article>
+			<p>Publicado em 18/06/2020 10h30</p>
+		</body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 18, 10, 30, 0, 0, brLoc)))
+		Expect(data.PublishedAtConfidence).To(Equal(0.6))
+	})
+
+	It("infers the publication date from the URL when no other source has one", func() {
+		body := `<html><body><p>Nothing here</p></body></html>`
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(body), "http://www.amazonas.am.gov.br/2020/05/casa-do-migrante/", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.Format("2006-01-02 15:04 MST")).To(Equal("2020-05-01 00:00 -04"))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionMonth))
+		Expect(data.PublishedAtConfidence).To(Equal(0.3))
+
+		body = `<html><head><meta property="article:published_time" content="2020-05-21 10:00"></head></html>`
+		data, err = NewArticleScraper(cfg).Run(ctx, []byte(body), "http://www.amazonas.am.gov.br/2020/05/20/casa-do-migrante/", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.Format("2006-01-02 15:04")).To(Equal("2020-05-21 10:00"))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionMinute))
+	})
+
+	It("supports custom URL date patterns", func() {
+		cfg.URLDatePatterns = []string{`/noticias/(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})-`}
+		body := `<html><body><p>Nothing here</p></body></html>`
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(body), "http://www.pe.gov.br/noticias/20200521-slug", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.PublishedAt.Format("2006-01-02")).To(Equal("2020-05-21"))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionDay))
+		Expect(data.PublishedAtConfidence).To(Equal(0.3))
+
+		cfg.URLDatePatterns = []string{`/(?P<year>\d{4})/`}
+		_, err = NewArticleScraper(cfg).Run(ctx, []byte(body), "http://www.pe.gov.br/2020/", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("prefers a more precise date that agrees with the most trusted one", func() {
+		listedAt := time.Date(2020, 6, 15, 0, 0, 0, 0, brLoc)
+		cfg.MergeWith = &ArticleData{PublishedAt: &listedAt, PublishedAtPrecision: dates.PrecisionDay}
+
+		data := run(`<html><body><p>Publicado: 15/06/2020 19h56</p></body></html>`)
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionMinute))
+		Expect(data.PublishedAtConfidence).To(Equal(0.92))
+
+		data = run(`<html><body><p>Publicado: 14/06/2020 19h56</p></body></html>`)
+		Expect(*data.PublishedAt).To(Equal(listedAt))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionDay))
+	})
+
+	It("takes custom extractors and data to merge into account", func() {
+		pubAt := time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)
+		cfg.MergeWith = &ArticleData{PublishedAt: &pubAt}
+		cfg.Extractors = append(cfg.Extractors, Structured("body", map[string]Extractor{
+			"publishedAt": TimeText(".date"),
+		}))
+
+		data := run(`<html><body><span class="date">15/06/2020 19h56</span></body></html>`)
+		Expect(*data.PublishedAt).To(Equal(pubAt))
+		Expect(data.PublishedAtConfidence).To(Equal(1.0))
+	})
+
+	It("prefers custom extractors and data to merge over the dates of the page", func() {
+		page := `<html><head>
+			<meta property="article:published_time" content="2020-06-10 10:00">
+		</head><body>
+			<article><time pubdate datetime="2020-06-10 10:00">10/06/2020</time></article>
+			<span class="date">15/06/2020 19h56</span>
+		</body></html>`
+		customAt := time.Date(2020, 6, 15, 19, 56, 0, 0, brLoc)
+		mergedAt := time.Date(2020, 6, 14, 8, 0, 0, 0, brLoc)
+
+		cfg.MergeWith = &ArticleData{PublishedAt: &mergedAt, PublishedAtPrecision: dates.PrecisionMinute}
+		data := run(page)
+		Expect(*data.PublishedAt).To(Equal(mergedAt))
+
+		cfg.Extractors = append(cfg.Extractors, Structured("body", map[string]Extractor{
+			"publishedAt": TimeText(".date"),
+		}))
+		data = run(page)
+		Expect(*data.PublishedAt).To(Equal(customAt))
+		Expect(data.PublishedAtConfidence).To(Equal(0.95))
+	})
+})
@@ -1,13 +1,41 @@
 package extractors
 
 import (
+	"fmt"
+	"net/url"
 	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fgrehm/brinfo/core/content"
+)
+
+// Engines that can be used for extracting the full text of articles.
+const (
+	ContentEngineHTMLInfo    = "htmlinfo"
+	ContentEngineMainContent = "main-content"
 )
 
-type basicArticleExtractor struct{}
+type basicArticleExtractor struct {
+	contentEngine string
+}
 
 func BasicArticle() Extractor {
-	return &basicArticleExtractor{}
+	return &basicArticleExtractor{contentEngine: ContentEngineHTMLInfo}
+}
+
+// BasicArticleWithContentEngine works like BasicArticle but extracts the full
+// text with the engine provided. ContentEngineMainContent removes boilerplate
+// like menus and sharing widgets that ContentEngineHTMLInfo keeps on some
+// pages, and is the one that extracts the structured full text of articles.
+func BasicArticleWithContentEngine(engine string) (Extractor, error) {
+	switch engine {
+	case "", ContentEngineHTMLInfo:
+		return BasicArticle(), nil
+	case ContentEngineMainContent:
+		return &basicArticleExtractor{contentEngine: engine}, nil
+	default:
+		return nil, fmt.Errorf("unknown content engine '%s'", engine)
+	}
 }
 
 func (e *basicArticleExtractor) Extract(args ExtractorArgs) (ExtractorResult, error) {
@@ -20,35 +48,105 @@ func (e *basicArticleExtractor) Extract(args ExtractorArgs) (ExtractorResult, er
 	if !ok {
 		panic("Something unexpected returned from htmlinfo")
 	}
+	e.extractContent(data, args)
 	if data["publishedAt"] == (*time.Time)(nil) {
 		if err = e.publishedAtFallbacks(data, args); err != nil {
 			return nil, err
 		}
+	} else {
+		data["dateSource"] = DateSourceOpenGraph
 	}
 
 	return data, nil
 }
 
+// extractContent sets the documents and media found on the main content of
+// the page and, with the main-content engine, the full text along with its
+// structured versions. The structured versions are left out with the htmlinfo engine
+// since the content kept by htmlinfo has its headings, lists and links
+// stripped.
+func (e *basicArticleExtractor) extractContent(data map[string]interface{}, args ExtractorArgs) {
+	main := content.MainContent(args.Root)
+	base, err := url.Parse(args.URL)
+	if err != nil {
+		base = nil
+	}
+
+	if e.contentEngine == ContentEngineMainContent {
+		title, _ := data["title"].(string)
+		excerpt, _ := data["excerpt"].(string)
+		data["fullText"] = cleanFullText(content.Text(main), title, excerpt)
+		data["fullTextHTML"] = content.HTML(main, base)
+		data["fullTextMarkdown"] = content.Markdown(main, base)
+	}
+	e.extractMedia(data, main, base)
+
+	attachments := []map[string]interface{}{}
+	for _, link := range content.DocumentLinks(main, base) {
+		attachments = append(attachments, map[string]interface{}{
+			"url":       link.URL,
+			"text":      link.Text,
+			"extension": link.Extension,
+			"mimeType":  link.MIMEType,
+		})
+	}
+	if len(attachments) > 0 {
+		data["attachments"] = attachments
+	}
+}
+
+// extractMedia sets the images and videos found on the main content. The
+// largest image is used as the image of the article unless OpenGraph provides
+// one with known dimensions, since the thumbnail guessed by htmlinfo is often
+// a logo or an icon.
+func (e *basicArticleExtractor) extractMedia(data map[string]interface{}, main *goquery.Selection, base *url.URL) {
+	found := content.FindMedia(main, base)
+	if len(found) == 0 {
+		return
+	}
+
+	media := []map[string]interface{}{}
+	for _, m := range found {
+		media = append(media, map[string]interface{}{
+			"type":    m.Type,
+			"url":     m.URL,
+			"caption": m.Caption,
+			"credit":  m.Credit,
+			"alt":     m.Alt,
+			"width":   m.Width,
+			"height":  m.Height,
+		})
+	}
+	data["media"] = media
+
+	if data["imageSource"] == ImageSourceOpenGraph {
+		return
+	}
+	if lead, ok := content.LeadImage(found); ok {
+		data["imageURL"] = lead.URL
+		data["imageSource"] = ImageSourceContent
+	}
+}
+
 func (e *basicArticleExtractor) publishedAtFallbacks(data map[string]interface{}, args ExtractorArgs) error {
-	if data["publishedAt"] == nil && data["modifiedAt"] != nil {
+	if data["modifiedAt"] != (*time.Time)(nil) {
 		data["publishedAt"] = data["modifiedAt"]
+		data["dateSource"] = DateSourceOpenGraph
 		return nil
 	}
 
-	val, err := PublishedDates().Extract(args)
+	dates, err := (&publishedDatesExtractor{}).extractWithFallbacks(args)
 	if err != nil {
 		return err
 	}
-	if val == nil {
+	if dates == nil {
 		return nil
 	}
-	extractedData, ok := val.(map[string]*time.Time)
-	if !ok {
-		panic("Returned something weird")
-	}
-	data["publishedAt"] = extractedData["publishedAt"]
-	data["modifiedAt"] = extractedData["modifiedAt"]
-	// TODO: Annotate with the source of the date here
+	data["publishedAt"] = dates.publishedAt
+	data["publishedAtPrecision"] = dates.publishedAtPrecision
+	data["modifiedAt"] = dates.modifiedAt
+	data["modifiedAtPrecision"] = dates.modifiedAtPrecision
+	data["dateSource"] = dates.source
 
 	return nil
 }
@@ -1,6 +1,8 @@
 package extractors_test
 
 import (
+	"strings"
+
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
 
 	. "github.com/onsi/ginkgo"
@@ -40,6 +42,102 @@ var _ = Describe("BasicArticle", func() {
 			"imageURL":    Equal("https://image.url"),
 		}))
 	})
+
+	Context("with the main-content engine", func() {
+		It("leaves boilerplate out of the full text", func() {
+			e, err := BasicArticleWithContentEngine(ContentEngineMainContent)
+			Expect(err).NotTo(HaveOccurred())
+
+			val, err := extract(e, basicArticleWithBoilerplateHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(MatchKeys(IgnoreExtras, Keys{
+				"title":            Equal("Article title"),
+				"fullText":         Equal("First paragraph of the article, which is long enough to be considered the main content of the page.\nSecond paragraph of the article."),
+				"fullTextHTML":     Equal("<p>First paragraph of the article, which is long enough to be considered the main content of the page.</p>\n<p>Second paragraph of the article.</p>"),
+				"fullTextMarkdown": Equal("First paragraph of the article, which is long enough to be considered the main content of the page.\n\nSecond paragraph of the article."),
+			}))
+		})
+
+		It("keeps the boilerplate with the htmlinfo engine", func() {
+			e, err := BasicArticleWithContentEngine(ContentEngineHTMLInfo)
+			Expect(err).NotTo(HaveOccurred())
+
+			val, err := extract(e, basicArticleWithBoilerplateHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.(map[string]interface{})["fullText"]).To(ContainSubstring("Compartilhe"))
+		})
+
+		It("leaves the structured full text out with the htmlinfo engine", func() {
+			val, err := extractURL(BasicArticle(), "https://www.es.gov.br/Noticia/vacinacao", basicArticleWithMediaHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).NotTo(HaveKey("fullTextHTML"))
+			Expect(val).NotTo(HaveKey("fullTextMarkdown"))
+		})
+	})
+
+	Context("media", func() {
+		var e Extractor
+
+		BeforeEach(func() {
+			e = BasicArticle()
+		})
+
+		It("returns the images and videos of the content", func() {
+			val, err := extractURL(e, "https://www.es.gov.br/Noticia/vacinacao", basicArticleWithMediaHTML)
+			Expect(err).NotTo(HaveOccurred())
+
+			media := val.(map[string]interface{})["media"]
+			Expect(media).To(HaveLen(2))
+			Expect(media).To(ContainElement(MatchAllKeys(Keys{
+				"type":    Equal("image"),
+				"url":     Equal("https://www.es.gov.br/Media/vacina.jpg"),
+				"caption": Equal("Vacinação no Centro de Vitória"),
+				"credit":  Equal("Fulano/Secom"),
+				"alt":     Equal("Vacina"),
+				"width":   Equal(800),
+				"height":  Equal(533),
+			})))
+			Expect(media).To(ContainElement(HaveKeyWithValue("url", "https://www.youtube.com/embed/abc123")))
+		})
+
+		It("returns the same media with the main-content engine", func() {
+			mainContent, err := BasicArticleWithContentEngine(ContentEngineMainContent)
+			Expect(err).NotTo(HaveOccurred())
+
+			expected, err := extractURL(e, "https://www.es.gov.br/Noticia/vacinacao", basicArticleWithMediaHTML)
+			Expect(err).NotTo(HaveOccurred())
+			val, err := extractURL(mainContent, "https://www.es.gov.br/Noticia/vacinacao", basicArticleWithMediaHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(HaveKeyWithValue("media", expected.(map[string]interface{})["media"]))
+		})
+
+		It("uses the largest image of the content when OpenGraph lacks one", func() {
+			val, err := extractURL(e, "https://www.es.gov.br/Noticia/vacinacao", basicArticleWithMediaHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(MatchKeys(IgnoreExtras, Keys{
+				"imageURL":    Equal("https://www.es.gov.br/Media/vacina.jpg"),
+				"imageSource": Equal(ImageSourceContent),
+			}))
+		})
+
+		It("keeps OpenGraph images with dimensions", func() {
+			html := strings.Replace(basicArticleWithMediaHTML, "<head>", `<head>
+				<meta property="og:image" content="https://www.es.gov.br/og.jpg">
+				<meta property="og:image:width" content="1200">
+				<meta property="og:image:height" content="630">`, 1)
+			val, err := extractURL(e, "https://www.es.gov.br/Noticia/vacinacao", html)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(MatchKeys(IgnoreExtras, Keys{
+				"imageURL":    Equal("https://www.es.gov.br/og.jpg"),
+				"imageSource": Equal(ImageSourceOpenGraph),
+			}))
+		})
+	})
+
+	It("fails for unknown content engines", func() {
+		_, err := BasicArticleWithContentEngine("whatever")
+		Expect(err).To(MatchError("unknown content engine 'whatever'"))
+	})
 })
 
 var basicArticleWithOGHTML = `<html>
@@ -71,3 +169,39 @@ var basicArticleWithMetaHTML = `<html>
 		</p>
 	</body>
 </html>`
+
+var basicArticleWithMediaHTML = `<html>
+	<head>
+		<title>Article title</title>
+	</head>
+	<body>
+		<header><img src="/imagens/logo.png" width="300" height="80"></header>
+		<div class="entry-content">
+			<p>First paragraph of the article, which is long enough to be considered the main content of the page.</p>
+			<figure>
+				<img src="/Media/vacina.jpg" alt="Vacina" width="800" height="533">
+				<figcaption>Vacinação no Centro de Vitória - Foto: Fulano/Secom</figcaption>
+			</figure>
+			<iframe src="https://www.youtube.com/embed/abc123"></iframe>
+		</div>
+	</body>
+</html>`
+
+var basicArticleWithBoilerplateHTML = `<html>
+	<head>
+		<title>Article title</title>
+	</head>
+	<body>
+		<nav><a href="/">Home</a><a href="/news">News</a></nav>
+		<article>
+			<h1>Article title</h1>
+			<div class="share"><span>Compartilhe:</span><a href="#">Facebook</a></div>
+			<div class="entry-content">
+				<p>First paragraph of the article, which is long enough to be considered the main content of the page.</p>
+				<p>Second paragraph of the article.</p>
+				<p>Compartilhe: <a href="#">Twitter</a></p>
+			</div>
+		</article>
+		<footer>Website</footer>
+	</body>
+</html>`
@@ -2,6 +2,9 @@ package extractors
 
 import (
 	"context"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -11,6 +14,12 @@ type ExtractorArgs struct {
 	URL             string
 	Root            *goquery.Selection
 	HTTPContentType string
+	Clock           dates.Clock
+	// Location is used for interpreting times that don't have an offset,
+	// dates.DefaultLocation is used when it is not set.
+	Location *time.Location
+
+	cachedDates *dateCache
 }
 
 func (a ExtractorArgs) WithRoot(root *goquery.Selection) ExtractorArgs {
@@ -18,6 +27,10 @@ func (a ExtractorArgs) WithRoot(root *goquery.Selection) ExtractorArgs {
 	return a
 }
 
+func (a ExtractorArgs) dateParser() *dates.Parser {
+	return &dates.Parser{Location: a.Location, Clock: a.Clock}
+}
+
 type Extractor interface {
 	Extract(args ExtractorArgs) (ExtractorResult, error)
 }
@@ -13,6 +13,13 @@ import (
 
 var trimTitleSuffixRegexp = regexp.MustCompile(`(.+)\|.*$`)
 
+// Names of the sources images are extracted from.
+const (
+	ImageSourceOpenGraph = "opengraph"
+	ImageSourceOEmbed    = "oembed"
+	ImageSourceContent   = "content"
+)
+
 type htmlinfoExtractor struct{}
 
 func HTMLInfo() Extractor {
@@ -40,10 +47,9 @@ func (e *htmlinfoExtractor) extractData(htmlInfo *htmlinfo.HTMLInfo, url string)
 	title := e.extractTitle(htmlInfo, oembed, siteName)
 	excerpt := e.extractExcerpt(htmlInfo, oembed, title)
 	fullText := e.extractFullText(htmlInfo, title, excerpt)
-	imageURL := e.extractImageURL(htmlInfo, oembed)
+	imageURL, imageSource := e.extractImageURL(htmlInfo, oembed)
 	publishedAt, modifiedAt := e.extractDates(htmlInfo)
 
-	// TODO: Consider returning canonical & opengraph url
 	result := map[string]interface{}{
 		"extra": map[string]interface{}{
 			"htmlinfo": map[string]interface{}{
@@ -51,12 +57,14 @@ func (e *htmlinfoExtractor) extractData(htmlInfo *htmlinfo.HTMLInfo, url string)
 				"generated_oembed": oembed,
 			},
 		},
-		"title":       title,
-		"excerpt":     excerpt,
-		"fullText":    fullText,
-		"imageURL":    imageURL,
-		"publishedAt": publishedAt,
-		"modifiedAt":  modifiedAt,
+		"canonicalURL": e.extractCanonicalURL(htmlInfo),
+		"title":        title,
+		"excerpt":      excerpt,
+		"fullText":     fullText,
+		"imageURL":     imageURL,
+		"imageSource":  imageSource,
+		"publishedAt":  publishedAt,
+		"modifiedAt":   modifiedAt,
 	}
 	return result, nil
 }
@@ -123,36 +131,55 @@ func (e *htmlinfoExtractor) extractFullText(htmlInfo *htmlinfo.HTMLInfo, title,
 		panic(err)
 	}
 
-	fullText := doc.Find("body").Text()
-	fullTextChunks := []string{}
-	for _, str := range strings.Split(fullText, "\n") {
+	return cleanFullText(doc.Find("body").Text(), title, excerpt)
+}
+
+// cleanFullText trims the lines of text, dropping empty ones and the ones
+// that repeat the title or the excerpt.
+func cleanFullText(text, title, excerpt string) string {
+	chunks := []string{}
+	for _, str := range strings.Split(text, "\n") {
 		str = strings.TrimSpace(str)
 		if str != "" && str != title && str != excerpt {
-			fullTextChunks = append(fullTextChunks, str)
+			chunks = append(chunks, str)
 		}
 	}
-	fullText = strings.Join(fullTextChunks, "\n")
-	return fullText
+	return strings.Join(chunks, "\n")
+}
+
+// extractCanonicalURL returns the URL declared by rel=canonical, falling back
+// to og:url. It is up to the scraper to decide whether it can be trusted.
+func (e *htmlinfoExtractor) extractCanonicalURL(htmlInfo *htmlinfo.HTMLInfo) string {
+	if htmlInfo.CanonicalURL != "" {
+		return htmlInfo.CanonicalURL
+	}
+	if htmlInfo.OGInfo != nil {
+		return htmlInfo.OGInfo.URL
+	}
+	return ""
 }
 
-func (e *htmlinfoExtractor) extractImageURL(htmlInfo *htmlinfo.HTMLInfo, oembed *oembed.Info) string {
+// extractImageURL returns the first OpenGraph image that has its dimensions
+// set, falling back to the thumbnail guessed by htmlinfo. The source of the
+// image is returned as well.
+func (e *htmlinfoExtractor) extractImageURL(htmlInfo *htmlinfo.HTMLInfo, oembed *oembed.Info) (string, string) {
 	if htmlInfo.OGInfo != nil && len(htmlInfo.OGInfo.Images) > 0 {
 		for _, img := range htmlInfo.OGInfo.Images {
 			if img.Width != 0 && img.Height != 0 {
 				if img.SecureURL != "" {
-					return img.SecureURL
+					return img.SecureURL, ImageSourceOpenGraph
 				}
 				if img.URL != "" {
-					return img.URL
+					return img.URL, ImageSourceOpenGraph
 				}
 			}
 		}
 	}
 
-	if oembed != nil {
-		return oembed.ThumbnailURL
+	if oembed != nil && oembed.ThumbnailURL != "" {
+		return oembed.ThumbnailURL, ImageSourceOEmbed
 	}
-	return ""
+	return "", ""
 }
 
 func (e *htmlinfoExtractor) extractDates(htmlInfo *htmlinfo.HTMLInfo) (*time.Time, *time.Time) {
@@ -25,6 +25,16 @@ var _ = Describe("HTMLInfo", func() {
 		}))
 	})
 
+	It("returns the canonical URL", func() {
+		val, err := extract(HTMLInfo(), articleWithOGHTML)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(HaveKeyWithValue("canonicalURL", "https://brinfo.io/article"))
+
+		val, err = extract(HTMLInfo(), articleWithMetaHTML)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(HaveKeyWithValue("canonicalURL", ""))
+	})
+
 	It("works when meta tags are present", func() {
 		e := HTMLInfo()
 
@@ -51,6 +61,7 @@ var articleWithOGHTML = `<html>
 		<meta property="og:image" content="https://image.url">
 		<meta property="article:published_time" content="2020-06-21T15:53:10-03:00">
 		<meta property="article:modified_time" content="2020-06-21T16:52:10-03:00">
+		<link rel="canonical" href="/article">
 	</head>
 	<body>
 		<p>Article body</p>
@@ -0,0 +1,176 @@
+package extractors
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fgrehm/brinfo/core/dates"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+const maxLabeledDatesTextLength = 300
+
+type labeledDatesTextExtractor struct {
+	*textExtractor
+}
+
+// LabeledDatesText extracts both the publishedAt and modifiedAt dates from the
+// text of a single element, like "Publicado: 12/05/2020 10h30, última
+// modificação: 13/05/2020 09h00". When used within a Structured extractor,
+// the dates are merged into its result instead of being nested under the
+// field name.
+func LabeledDatesText(selector string) Extractor {
+	return &labeledDatesTextExtractor{
+		textExtractor: &textExtractor{
+			selector: selector,
+			multiple: false,
+			required: true,
+		},
+	}
+}
+
+func OptLabeledDatesText(selector string) Extractor {
+	return &labeledDatesTextExtractor{
+		textExtractor: &textExtractor{
+			selector: selector,
+			multiple: false,
+			required: false,
+		},
+	}
+}
+
+func (e *labeledDatesTextExtractor) Extract(args ExtractorArgs) (ExtractorResult, error) {
+	res, err := e.textExtractor.Extract(args)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+
+	str, ok := res.(string)
+	if !ok {
+		return nil, errors.New("textExtractor returned something not a string")
+	}
+
+	parser := args.dateParser()
+	labeled := parser.ParseLabeled(str)
+	if labeled == nil {
+		if e.textExtractor.required {
+			return nil, errors.New("unable to find labeled dates")
+		}
+		return nil, nil
+	}
+
+	return map[string]*time.Time{
+		"publishedAt": labeled.PublishedAt,
+		"modifiedAt":  labeled.ModifiedAt,
+	}, nil
+}
+
+func (e *labeledDatesTextExtractor) mergeIntoParent() {}
+
+// findLabeledDates looks for the element with the shortest text that has the
+// most labeled dates in it.
+func findLabeledDates(args ExtractorArgs) *dates.LabeledDates {
+	var (
+		best       *dates.LabeledDates
+		bestCount  int
+		bestLength int
+		parser     = args.dateParser()
+	)
+
+	texts := map[*html.Node]string{}
+	for _, n := range args.Root.Nodes {
+		shortTexts(n, texts)
+	}
+
+	args.Root.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		text, ok := texts[s.Get(0)]
+		if !ok || text == "" {
+			return
+		}
+
+		labeled := parser.ParseLabeled(text)
+		if labeled == nil {
+			return
+		}
+
+		count := 0
+		if labeled.PublishedAt != nil {
+			count++
+		}
+		if labeled.ModifiedAt != nil {
+			count++
+		}
+		if count > bestCount || (count == bestCount && len(text) < bestLength) {
+			best, bestCount, bestLength = labeled, count, len(text)
+		}
+	})
+
+	return best
+}
+
+// shortTexts records on texts the text of the elements within n that are at
+// most maxLabeledDatesTextLength long, with whitespace collapsed. The text of
+// each element is built from the ones of its children so that the page is
+// walked once. It returns the text of n and false if it is too long.
+func shortTexts(n *html.Node, texts map[*html.Node]string) (string, bool) {
+	switch n.Type {
+	case html.TextNode:
+		return collapseWhitespace(n.Data), len(strings.TrimSpace(n.Data)) <= maxLabeledDatesTextLength
+	case html.ElementNode:
+		switch n.Data {
+		case "script", "style", "noscript":
+			return "", true
+		}
+	case html.DocumentNode:
+	default:
+		return "", true
+	}
+
+	var buf strings.Builder
+	short := true
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text, ok := shortTexts(c, texts)
+		if short && ok {
+			buf.WriteString(text)
+			short = len(strings.TrimSpace(buf.String())) <= maxLabeledDatesTextLength
+		} else {
+			short = false
+		}
+	}
+	if !short {
+		return "", false
+	}
+
+	text := collapseWhitespace(buf.String())
+	if n.Type == html.ElementNode {
+		texts[n] = strings.TrimSpace(text)
+	}
+	return text, true
+}
+
+// collapseWhitespace replaces runs of whitespace with a single space, keeping
+// the ones at the edges so that the text of siblings stays apart.
+func collapseWhitespace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s == "" {
+			return ""
+		}
+		return " "
+	}
+	text := strings.Join(fields, " ")
+	if strings.TrimLeftFunc(s, unicode.IsSpace) != s {
+		text = " " + text
+	}
+	if strings.TrimRightFunc(s, unicode.IsSpace) != s {
+		text += " "
+	}
+	return text
+}
@@ -0,0 +1,60 @@
+package extractors_test
+
+import (
+	"time"
+
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabeledDatesText", func() {
+	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+	pubAt := time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)
+	modAt := time.Date(2020, 5, 13, 9, 0, 0, 0, brLoc)
+	html := `<div class="byline">Publicado: 12/05/2020 10h30, última modificação: 13/05/2020 09h00</div>`
+
+	It("extracts both dates from a single element", func() {
+		val, err := extract(LabeledDatesText(".byline"), html)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(Equal(map[string]*time.Time{
+			"publishedAt": &pubAt,
+			"modifiedAt":  &modAt,
+		}))
+	})
+
+	It("errors if no labeled dates are found", func() {
+		val, err := extract(LabeledDatesText(".byline"), `<div class="byline">Por Secom</div>`)
+		Expect(err).To(HaveOccurred())
+		Expect(val).To(BeNil())
+	})
+
+	It("can be made optional", func() {
+		val, err := extract(OptLabeledDatesText(".byline"), `<div class="byline">Por Secom</div>`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(BeNil())
+	})
+
+	It("is supported by loaders and merged into structured results", func() {
+		e, err := FromString(".byline | text::dates")
+		Expect(err).NotTo(HaveOccurred())
+		val, err := extract(e, html)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(HaveKeyWithValue("publishedAt", &pubAt))
+
+		extractors, err := FromJSON([]byte(`{"title": "h1 | text", "dates": ".byline | text::dates"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extractors).To(HaveLen(1))
+		val, err = extract(extractors[0], `<h1>Title</h1>`+html)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(val).To(Equal(map[string]ExtractorResult{
+			"title":       "Title",
+			"publishedAt": pubAt,
+			"modifiedAt":  modAt,
+		}))
+	})
+})
@@ -4,59 +4,89 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 )
 
-var extractorSpecRegexp = regexp.MustCompile(`^\s*([^\\|]+\S?)\s*\|\s*([\w]+)(\?)?(?:::(time))?\s*$`)
+var extractorSpecRegexp = regexp.MustCompile(`^\s*([^\\|]+\S?)\s*\|\s*([\w]+)(\?)?(?:::(time|dates))?\s*$`)
 
-func FromString(extractorStr string) (Extractor, error) {
+// Spec is the parsed representation of an extractor string in the
+// `selector | attribute[?][::cast]` format.
+type Spec struct {
+	Selector  string
+	Attribute string
+	Required  bool
+	CastTo    string
+}
+
+func ParseSpec(extractorStr string) (*Spec, error) {
 	match := extractorSpecRegexp.FindStringSubmatch(extractorStr)
 	if len(match) < 3 {
 		return nil, fmt.Errorf("Invalid extractor provided: %s", extractorStr)
 	}
 
-	selector := match[1]
-	attribute := match[2]
+	spec := &Spec{
+		Selector:  strings.TrimSpace(match[1]),
+		Attribute: match[2],
+		CastTo:    match[4],
+		Required:  true,
+	}
 	modifier := match[3]
-	castTo := match[4]
 
-	if castTo != "" && castTo != "time" {
-		return nil, fmt.Errorf("cast to %s not supported", castTo)
+	if spec.CastTo != "" && spec.CastTo != "time" && spec.CastTo != "dates" {
+		return nil, fmt.Errorf("cast to %s not supported", spec.CastTo)
 	}
 
-	required := true
 	if modifier != "" {
 		if modifier == "?" {
-			required = false
+			spec.Required = false
 		} else {
 			return nil, fmt.Errorf("modifier %s not supported", modifier)
 		}
 	}
 
-	if castTo == "time" {
-		if attribute == "text" {
-			if required {
-				return TimeText(selector), nil
+	return spec, nil
+}
+
+func FromString(extractorStr string) (Extractor, error) {
+	spec, err := ParseSpec(extractorStr)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Extractor(), nil
+}
+
+func (s *Spec) Extractor() Extractor {
+	if s.CastTo == "dates" {
+		if s.Required {
+			return LabeledDatesText(s.Selector)
+		} else {
+			return OptLabeledDatesText(s.Selector)
+		}
+	} else if s.CastTo == "time" {
+		if s.Attribute == "text" {
+			if s.Required {
+				return TimeText(s.Selector)
 			} else {
-				return OptTimeText(selector), nil
+				return OptTimeText(s.Selector)
 			}
 		} else {
-			if required {
-				return TimeAttribute(selector, attribute), nil
+			if s.Required {
+				return TimeAttribute(s.Selector, s.Attribute)
 			} else {
-				return OptTimeAttribute(selector, attribute), nil
+				return OptTimeAttribute(s.Selector, s.Attribute)
 			}
 		}
-	} else if attribute == "text" {
-		if required {
-			return Text(selector, false), nil
+	} else if s.Attribute == "text" {
+		if s.Required {
+			return Text(s.Selector, false)
 		} else {
-			return OptText(selector, false), nil
+			return OptText(s.Selector, false)
 		}
 	} else {
-		if required {
-			return Attribute(selector, attribute), nil
+		if s.Required {
+			return Attribute(s.Selector, s.Attribute)
 		} else {
-			return OptAttribute(selector, attribute), nil
+			return OptAttribute(s.Selector, s.Attribute)
 		}
 	}
 }
@@ -109,8 +139,8 @@ func normalizeAttributeName(attr string) string {
 	switch attr {
 	case "published_at":
 		return "publishedAt"
-	case "updated_at", "modified_at", "modifiedAt":
-		return "updatedAt"
+	case "updated_at", "updatedAt", "modified_at":
+		return "modifiedAt"
 	case "image_url":
 		return "imageURL"
 	case "full_text":
@@ -1,8 +1,11 @@
 package extractors_test
 
 import (
+	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
+
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
 
 	. "github.com/onsi/ginkgo"
@@ -132,6 +135,38 @@ var _ = Describe("Loaders", func() {
 		})
 	})
 
+	Describe("ParseSpec", func() {
+		It("parses all parts of the extractor string", func() {
+			spec, err := ParseSpec("time.pub | pubdate?::time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec).To(Equal(&Spec{
+				Selector:  "time.pub",
+				Attribute: "pubdate",
+				Required:  false,
+				CastTo:    "time",
+			}))
+		})
+
+		It("returns every node matched along with raw and parsed values", func() {
+			spec, err := ParseSpec("p span | text::time")
+			Expect(err).NotTo(HaveOccurred())
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<p><span>20/03/2020 18:30</span><span>foo</span></p>`))
+			Expect(err).NotTo(HaveOccurred())
+
+			matches, err := spec.Matches(ExtractorArgs{Root: doc.Selection})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(HaveLen(2))
+			Expect(matches[0].OuterHTML).To(Equal("<span>20/03/2020 18:30</span>"))
+			Expect(matches[0].Raw).To(Equal("20/03/2020 18:30"))
+			Expect(matches[0].Value).To(Equal(time.Date(2020, 3, 20, 18, 30, 0, 0, brLoc)))
+			Expect(matches[0].Err).NotTo(HaveOccurred())
+			Expect(matches[1].Raw).To(Equal("foo"))
+			Expect(matches[1].Value).To(BeNil())
+			Expect(matches[1].Err).To(HaveOccurred())
+		})
+	})
+
 	Describe("FromJSON", func() {
 		It("works for top level fields", func() {
 			e, err := FromJSON([]byte(`{"title": "p a | href"}`))
@@ -179,6 +214,18 @@ var _ = Describe("Loaders", func() {
 			Expect(val).To(Equal(map[string]ExtractorResult{"fullText": "full text"}))
 		})
 
+		It("maps modified dates to modifiedAt", func() {
+			for _, attr := range []string{"updated_at", "updatedAt", "modified_at"} {
+				e, err := FromJSON([]byte(`{"` + attr + `": "time | text"}`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(e)).To(Equal(1))
+
+				val, err := extract(e[0], `<html><body><time>12/05/2020</time></body></html>`)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(val).To(HaveKey("modifiedAt"))
+			}
+		})
+
 		It("errors if can't parse extractors", func() {
 			e, err := FromJSON([]byte(`{"full_text": "p"}`))
 			Expect(err).To(HaveOccurred())
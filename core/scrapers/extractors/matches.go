@@ -0,0 +1,72 @@
+package extractors
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Match describes a node matched by the selector of a Spec, used for
+// debugging extractors.
+type Match struct {
+	OuterHTML string
+	Raw       string
+	Found     bool
+	Value     interface{}
+	Err       error
+}
+
+// Matches returns every node matched by the spec selector along with the raw
+// value found on it and the value it would get parsed into.
+func (s *Spec) Matches(args ExtractorArgs) ([]*Match, error) {
+	matches := []*Match{}
+	var err error
+
+	args.Root.Find(s.Selector).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		m := &Match{}
+		m.OuterHTML, err = goquery.OuterHtml(sel)
+		if err != nil {
+			return false
+		}
+
+		if s.Attribute == "text" {
+			m.Raw, m.Found = sel.Text(), true
+		} else {
+			m.Raw, m.Found = sel.Attr(s.Attribute)
+		}
+		m.Value = strings.TrimSpace(m.Raw)
+
+		if s.CastTo == "dates" && m.Found {
+			parser := args.dateParser()
+			if labeled := parser.ParseLabeled(m.Raw); labeled != nil {
+				m.Value = map[string]*time.Time{
+					"publishedAt": labeled.PublishedAt,
+					"modifiedAt":  labeled.ModifiedAt,
+				}
+			} else {
+				m.Value = nil
+				m.Err = errors.New("unable to find labeled dates")
+			}
+		}
+
+		if s.CastTo == "time" && m.Found {
+			t, _, err := parseExtractedTime(args, strings.TrimSpace(m.Raw))
+			if t != nil {
+				m.Value = *t
+			} else {
+				m.Value = nil
+			}
+			m.Err = err
+		}
+
+		matches = append(matches, m)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
@@ -2,24 +2,109 @@ package extractors
 
 import (
 	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+
+	"golang.org/x/net/html"
 )
 
-type publishedDatesExtractor struct {
-	brLoc time.Location
-}
+type publishedDatesExtractor struct{}
+
+// Names of the sources dates are extracted from.
+const (
+	DateSourceOpenGraph   = "opengraph"
+	DateSourceRNews       = "rnews"
+	DateSourceTime        = "time"
+	DateSourceLabeledText = "labeled_text"
+)
 
 type extractedDates struct {
-	publishedAt *time.Time
-	modifiedAt  *time.Time
+	source               string
+	publishedAt          *time.Time
+	publishedAtPrecision dates.Precision
+	modifiedAt           *time.Time
+	modifiedAtPrecision  dates.Precision
 }
 
-func PublishedDates() Extractor {
-	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+type dateSource struct {
+	name    string
+	extract func(args ExtractorArgs) (*extractedDates, error)
+}
+
+// DateCandidate holds the dates found on one of the sources of a page.
+type DateCandidate struct {
+	Source               string
+	PublishedAt          *time.Time
+	PublishedAtPrecision dates.Precision
+	ModifiedAt           *time.Time
+	ModifiedAtPrecision  dates.Precision
+}
+
+// dateCache keeps the dates found on each source of a page, so that the
+// extractors and DateCandidates look them up once.
+type dateCache struct {
+	results map[dateCacheKey]*extractedDates
+}
+
+type dateCacheKey struct {
+	root   *html.Node
+	source string
+}
+
+// WithDateCache returns args with a cache for the dates found on the page,
+// which should be used for running all extractors over the same page.
+func (a ExtractorArgs) WithDateCache() ExtractorArgs {
+	a.cachedDates = &dateCache{results: map[dateCacheKey]*extractedDates{}}
+	return a
+}
+
+// run extracts the dates of source, reusing the dates found by a previous run over the same
+// root when args have a cache.
+func (source dateSource) run(args ExtractorArgs) (*extractedDates, error) {
+	if args.cachedDates == nil {
+		return source.extract(args)
+	}
+	key := dateCacheKey{source: source.name}
+	if args.Root != nil && args.Root.Length() > 0 {
+		key.root = args.Root.Get(0)
+	}
+	if result, ok := args.cachedDates.results[key]; ok {
+		return result, nil
+	}
+	result, err := source.extract(args)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	args.cachedDates.results[key] = result
+	return result, nil
+}
 
-	return &publishedDatesExtractor{brLoc: *brLoc}
+// DateCandidates looks up dates on all sources supported by PublishedDates
+// instead of stopping at the first one that has them.
+func DateCandidates(args ExtractorArgs) ([]*DateCandidate, error) {
+	e := &publishedDatesExtractor{}
+	candidates := []*DateCandidate{}
+	for _, source := range e.sources() {
+		result, err := source.run(args)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			continue
+		}
+		candidates = append(candidates, &DateCandidate{
+			Source:               source.name,
+			PublishedAt:          result.publishedAt,
+			PublishedAtPrecision: result.publishedAtPrecision,
+			ModifiedAt:           result.modifiedAt,
+			ModifiedAtPrecision:  result.modifiedAtPrecision,
+		})
+	}
+	return candidates, nil
+}
+
+func PublishedDates() Extractor {
+	return &publishedDatesExtractor{}
 }
 
 func (e *publishedDatesExtractor) Extract(args ExtractorArgs) (ExtractorResult, error) {
@@ -39,31 +124,30 @@ func (e *publishedDatesExtractor) Extract(args ExtractorArgs) (ExtractorResult,
 }
 
 func (e *publishedDatesExtractor) extractWithFallbacks(args ExtractorArgs) (*extractedDates, error) {
-	result, err := e.extractFromMeta(args)
-	if err != nil {
-		return nil, err
-	}
-	if result != nil {
-		return result, nil
-	}
-
-	result, err = e.extractFromRNews(args)
-	if err != nil {
-		return nil, err
-	}
-	if result != nil {
-		return result, nil
+	for _, source := range e.sources() {
+		result, err := source.run(args)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			result.source = source.name
+			return result, nil
+		}
 	}
+	return nil, nil
+}
 
-	result, err = e.extractFromArticleTime(args)
-	if err != nil {
-		return nil, err
+// sources returns the places dates are looked up on a page, sorted by how
+// much they are trusted.
+func (e *publishedDatesExtractor) sources() []dateSource {
+	return []dateSource{
+		{DateSourceOpenGraph, e.extractFromMeta},
+		{DateSourceRNews, e.extractFromRNews},
+		{DateSourceTime, e.extractFromArticleTime},
+		{DateSourceLabeledText, func(args ExtractorArgs) (*extractedDates, error) {
+			return e.extractFromLabeledText(args), nil
+		}},
 	}
-	if result != nil {
-		return result, nil
-	}
-
-	return nil, nil
 }
 
 func (e *publishedDatesExtractor) extractFromMeta(args ExtractorArgs) (*extractedDates, error) {
@@ -102,6 +186,19 @@ func (e *publishedDatesExtractor) extractFromArticleTime(args ExtractorArgs) (*e
 	return e.handleExtractedResult(extractor.Extract(args))
 }
 
+func (e *publishedDatesExtractor) extractFromLabeledText(args ExtractorArgs) *extractedDates {
+	labeled := findLabeledDates(args)
+	if labeled == nil {
+		return nil
+	}
+	return &extractedDates{
+		publishedAt:          labeled.PublishedAt,
+		publishedAtPrecision: labeled.PublishedAtPrecision,
+		modifiedAt:           labeled.ModifiedAt,
+		modifiedAtPrecision:  labeled.ModifiedAtPrecision,
+	}
+}
+
 func (e *publishedDatesExtractor) handleExtractedResult(extracted ExtractorResult, err error) (*extractedDates, error) {
 	if extracted == nil {
 		return nil, nil
@@ -123,12 +220,14 @@ func (e *publishedDatesExtractor) handleExtractedResult(extracted ExtractorResul
 	if publishedAt != nil {
 		pubAt := publishedAt.(time.Time)
 		data.publishedAt = &pubAt
+		data.publishedAtPrecision, _ = extractedMap[PrecisionField("published_at")].(dates.Precision)
 	}
 
 	modifiedAt := extractedMap["modified_at"]
 	if modifiedAt != nil {
 		modAt := modifiedAt.(time.Time)
 		data.modifiedAt = &modAt
+		data.modifiedAtPrecision, _ = extractedMap[PrecisionField("modified_at")].(dates.Precision)
 	}
 
 	return data, nil
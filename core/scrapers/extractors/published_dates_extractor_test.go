@@ -1,6 +1,7 @@
 package extractors_test
 
 import (
+	"strings"
 	"time"
 
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
@@ -111,6 +112,63 @@ var _ = Describe("PublishedDates", func() {
 		})
 	})
 
+	Context("labeled text", func() {
+		It("extracts dates from the element with labeled dates", func() {
+			e := PublishedDates()
+
+			val, err := extract(e, `<body>
+				<h1>Title</h1>
+				<div class="info">
+					<p>Por Secom</p>
+					<p class="dates"><span>Publicado:</span> 21/02/2010 15h50, <span>última modificação:</span> 22/02/2010 10h00</p>
+				</div>
+				<p>Article body published in 01/01/2010</p>
+			</body>`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).NotTo(BeNil())
+
+			data, ok := val.(map[string]*time.Time)
+			if !ok {
+				panic("Returned something weird")
+			}
+			Expect(*data["publishedAt"]).To(Equal(time.Date(2010, 2, 21, 15, 50, 0, 0, brLoc)))
+			Expect(*data["modifiedAt"]).To(Equal(time.Date(2010, 2, 22, 10, 0, 0, 0, brLoc)))
+		})
+
+		It("ignores long texts and scripts", func() {
+			e := PublishedDates()
+
+			val, err := extract(e, `<body>
+				<script>var label = "Publicado: 01/01/2009";</script>
+				<div class="content">
+					<p>A comissão foi criada em 12/03/2009. `+strings.Repeat("Texto da notícia. ", 30)+` Publicado: 01/01/2009</p>
+					<p class="dates">Publicado em 21/02/2010 15h50</p>
+				</div>
+			</body>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, ok := val.(map[string]*time.Time)
+			if !ok {
+				panic("Returned something weird")
+			}
+			Expect(*data["publishedAt"]).To(Equal(time.Date(2010, 2, 21, 15, 50, 0, 0, brLoc)))
+		})
+
+		It("is used only when no other source is found", func() {
+			e := PublishedDates()
+
+			val, err := extract(e, `<html><head><meta property="article:published_time" content="2010-02-20 15:50"></head>
+				<body><p>Publicado: 21/02/2010 15h50</p></body></html>`)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, ok := val.(map[string]*time.Time)
+			if !ok {
+				panic("Returned something weird")
+			}
+			Expect(*data["publishedAt"]).To(Equal(time.Date(2010, 2, 20, 15, 50, 0, 0, brLoc)))
+		})
+	})
+
 	Context("rnews", func() {
 		It("extracts publishedAt from rnews:datePublished", func() {
 			e := PublishedDates()
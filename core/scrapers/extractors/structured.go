@@ -2,6 +2,9 @@ package extractors
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -76,13 +79,53 @@ func (e *structuredExtractor) Extract(args ExtractorArgs) (ExtractorResult, erro
 func (e *structuredExtractor) extractOne(args ExtractorArgs) (map[string]ExtractorResult, error) {
 	ret := map[string]ExtractorResult{}
 	for fieldName, extractor := range e.configs {
-		result, err := extractor.Extract(args)
+		var (
+			result    ExtractorResult
+			precision dates.Precision
+			err       error
+		)
+		if pe, ok := extractor.(precisionExtractor); ok {
+			result, precision, err = pe.extractWithPrecision(args)
+		} else {
+			result, err = extractor.Extract(args)
+		}
 		if err != nil {
 			// errors.WithStack
 			return nil, fmt.Errorf("within '%s' > %s", e.selector, err)
 		}
+		if result != nil && precision != "" {
+			ret[PrecisionField(fieldName)] = precision
+		}
+		if _, ok := extractor.(multipleFieldsExtractor); ok {
+			if err := mergeFields(ret, result); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		ret[fieldName] = result
 	}
 
 	return ret, nil
 }
+
+// multipleFieldsExtractor is implemented by extractors that produce values for
+// multiple fields, which get merged into the structured result instead of
+// being nested under the field name.
+type multipleFieldsExtractor interface {
+	mergeIntoParent()
+}
+
+func mergeFields(ret map[string]ExtractorResult, result ExtractorResult) error {
+	switch fields := result.(type) {
+	case nil:
+	case map[string]*time.Time:
+		for k, v := range fields {
+			if v != nil {
+				ret[k] = *v
+			}
+		}
+	default:
+		return fmt.Errorf("unable to merge %T into structured result", result)
+	}
+	return nil
+}
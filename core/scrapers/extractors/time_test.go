@@ -1,8 +1,11 @@
 package extractors_test
 
 import (
+	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
+
 	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
 
 	. "github.com/onsi/ginkgo"
@@ -62,6 +65,21 @@ var _ = Describe("Date extractors", func() {
 			Expect(val).To(Equal(time.Date(2020, 3, 22, 16, 22, 0, 0, brLoc)))
 		})
 
+		It("parses date only and relative dates", func() {
+			e := TimeText("span")
+
+			val, err := extract(e, `<p><span>Terça-feira, 1 de dezembro de 2020</span></p>`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(Equal(time.Date(2020, 12, 1, 0, 0, 0, 0, brLoc)))
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<p><span>ontem às 10h30</span></p>`))
+			Expect(err).NotTo(HaveOccurred())
+			now := time.Date(2020, 5, 14, 15, 20, 0, 0, brLoc)
+			val, err = e.Extract(ExtractorArgs{Root: doc.Selection, Clock: fakeClock{now}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(Equal(time.Date(2020, 5, 13, 10, 30, 0, 0, brLoc)))
+		})
+
 		It("errors if element not found", func() {
 			e := TimeText("span")
 
@@ -111,3 +129,11 @@ var _ = Describe("Date extractors", func() {
 		})
 	})
 })
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
@@ -0,0 +1,92 @@
+package extractors
+
+import (
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+)
+
+// DateSourceURL is the source of dates inferred from the URL of the page.
+const DateSourceURL = "url"
+
+// DefaultURLDatePatterns match permalinks like the ones generated by
+// WordPress (/2020/05/slug/ or /2020/05/21/slug/).
+var DefaultURLDatePatterns = []string{
+	`/(?P<year>(?:19|20)\d{2})/(?P<month>0[1-9]|1[0-2])/(?P<day>0[1-9]|[12]\d|3[01])/`,
+	`/(?P<year>(?:19|20)\d{2})-(?P<month>0[1-9]|1[0-2])-(?P<day>0[1-9]|[12]\d|3[01])(?:[/_-]|$)`,
+	`/(?P<year>(?:19|20)\d{2})/(?P<month>0[1-9]|1[0-2])/`,
+}
+
+type urlDateExtractor struct {
+	patterns []*regexp.Regexp
+}
+
+// URLDate infers the publication date from the path of the page URL using
+// regular expressions with "year", "month" and an optional "day" named
+// groups. Patterns are tried in order and the first match wins.
+func URLDate(patterns ...string) (Extractor, error) {
+	e := &urlDateExtractor{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if subexpIndex(re, "year") < 0 || subexpIndex(re, "month") < 0 {
+			return nil, fmt.Errorf("URL date pattern must have year and month groups: %s", pattern)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+	return e, nil
+}
+
+func (e *urlDateExtractor) Extract(args ExtractorArgs) (ExtractorResult, error) {
+	u, err := neturl.Parse(args.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, re := range e.patterns {
+		match := re.FindStringSubmatch(u.Path)
+		if match == nil {
+			continue
+		}
+
+		year, _ := strconv.Atoi(match[subexpIndex(re, "year")])
+		month, _ := strconv.Atoi(match[subexpIndex(re, "month")])
+		day, precision := 1, dates.PrecisionMonth
+		if i := subexpIndex(re, "day"); i >= 0 && match[i] != "" {
+			day, _ = strconv.Atoi(match[i])
+			precision = dates.PrecisionDay
+		}
+
+		loc := args.Location
+		if loc == nil {
+			loc = dates.DefaultLocation
+		}
+		publishedAt := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+		if publishedAt.Day() != day || publishedAt.Month() != time.Month(month) {
+			return nil, nil
+		}
+
+		return map[string]interface{}{
+			"publishedAt":          &publishedAt,
+			"publishedAtPrecision": precision,
+			"dateSource":           DateSourceURL,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func subexpIndex(re *regexp.Regexp, name string) int {
+	for i, n := range re.SubexpNames() {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package extractors_test
+
+import (
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("URLDate", func() {
+	brLoc := dates.DefaultLocation
+
+	cases := []struct {
+		url       string
+		expected  time.Time
+		precision dates.Precision
+	}{
+		{"http://www.amazonas.am.gov.br/2020/05/casa-do-migrante/", time.Date(2020, 5, 1, 0, 0, 0, 0, brLoc), dates.PrecisionMonth},
+		{"http://www.example.gov.br/2020/05/21/slug/", time.Date(2020, 5, 21, 0, 0, 0, 0, brLoc), dates.PrecisionDay},
+		{"http://www.example.gov.br/noticias/2020-05-21-slug", time.Date(2020, 5, 21, 0, 0, 0, 0, brLoc), dates.PrecisionDay},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("infers the date from "+c.url, func() {
+			e, err := URLDate(DefaultURLDatePatterns...)
+			Expect(err).NotTo(HaveOccurred())
+
+			val, err := extractURL(e, c.url, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(Equal(map[string]interface{}{
+				"publishedAt":          &c.expected,
+				"publishedAtPrecision": c.precision,
+				"dateSource":           DateSourceURL,
+			}))
+		})
+	}
+
+	It("returns nothing for URLs without dates", func() {
+		e, err := URLDate(DefaultURLDatePatterns...)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, url := range []string{
+			"http://www.example.gov.br/noticias/2020/",
+			"http://www.example.gov.br/2020/13/slug/",
+			"http://www.example.gov.br/2020/02/31/slug/",
+			"http://www.example.gov.br/noticias?ano=2020&mes=05",
+		} {
+			val, err := extractURL(e, url, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val).To(BeNil(), url)
+		}
+	})
+
+	It("errors on invalid patterns", func() {
+		_, err := URLDate(`/(?P<year>\d{4})/`)
+		Expect(err).To(HaveOccurred())
+
+		_, err = URLDate(`/(?P<year>\d{4}/`)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,260 @@
+package scrapers
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	minListingItems    = 3
+	listingPreviewSize = 5
+)
+
+var (
+	safeIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_-][\w-]*$`)
+	dateLikeRegexp       = regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{2,4}|\d{4}-\d{2}-\d{2}|\d{1,2} de \S+ de \d{4}`)
+)
+
+// ListingSuggestion is a candidate configuration for an ArticleListScraper
+// inferred from the structure of a page.
+type ListingSuggestion struct {
+	Config  *ArticleListScraperConfig
+	Items   int
+	Score   float64
+	Preview []*core.ArticleLink
+}
+
+// SuggestListingConfigs analyzes the DOM for repeated sibling elements that
+// contain links and returns candidate configurations ranked by how likely they
+// are to represent a list of articles.
+func SuggestListingConfigs(ctx context.Context, body []byte, url string) ([]*ListingSuggestion, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	suggestions := []*ListingSuggestion{}
+
+	doc.Find("*").Each(func(_ int, parent *goquery.Selection) {
+		for _, container := range repeatedChildrenSelectors(parent) {
+			if seen[container] {
+				continue
+			}
+			seen[container] = true
+
+			suggestion := suggestFromContainer(doc.Selection, container)
+			if suggestion == nil {
+				continue
+			}
+
+			scraper, err := NewArticleListScraper(suggestion.Config)
+			if err != nil {
+				continue
+			}
+			links, err := scraper.Run(ctx, body, url, "")
+			if err != nil {
+				continue
+			}
+			if len(links) > listingPreviewSize {
+				links = links[:listingPreviewSize]
+			}
+			suggestion.Preview = links
+
+			suggestions = append(suggestions, suggestion)
+		}
+	})
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	return suggestions, nil
+}
+
+// repeatedChildrenSelectors returns selectors for groups of children of parent
+// that share the same tag and classes.
+func repeatedChildrenSelectors(parent *goquery.Selection) []string {
+	path := pathSelector(parent)
+	if path == "" {
+		return nil
+	}
+
+	counts := map[string]int{}
+	order := []string{}
+	parent.Children().Each(func(_ int, child *goquery.Selection) {
+		sig := nodeSelector(child, false)
+		if counts[sig] == 0 {
+			order = append(order, sig)
+		}
+		counts[sig]++
+	})
+
+	selectors := []string{}
+	for _, sig := range order {
+		if counts[sig] >= minListingItems {
+			selectors = append(selectors, path+" > "+sig)
+		}
+	}
+	return selectors
+}
+
+func suggestFromContainer(root *goquery.Selection, container string) *ListingSuggestion {
+	items := root.Find(container)
+	if items.Length() < minListingItems {
+		return nil
+	}
+
+	withLinks := 0
+	linkTextLength := 0
+	items.Each(func(_ int, item *goquery.Selection) {
+		link := item.Find("a[href]").First()
+		if link.Length() > 0 {
+			withLinks++
+			linkTextLength += len(strings.TrimSpace(link.Text()))
+		}
+	})
+	if withLinks < items.Length()*8/10 {
+		return nil
+	}
+
+	urlExtractor := suggestLinkExtractor(items)
+	if urlExtractor == "" {
+		return nil
+	}
+
+	cfg := &ArticleListScraperConfig{
+		LinkContainer:        container,
+		URLExtractor:         urlExtractor,
+		PublishedAtExtractor: suggestDateExtractor(items),
+		ImageURLExtractor:    suggestImageExtractor(items),
+	}
+
+	score := float64(withLinks)
+	// Menus usually have short link texts while article titles are longer
+	if avg := linkTextLength / withLinks; avg < 20 {
+		score *= float64(avg) / 20
+	}
+	if cfg.PublishedAtExtractor != "" {
+		score *= 2
+	}
+	if cfg.ImageURLExtractor != "" {
+		score *= 1.5
+	}
+	if items.First().Closest("nav, header, footer, aside").Length() > 0 {
+		score *= 0.2
+	}
+
+	return &ListingSuggestion{Config: cfg, Items: items.Length(), Score: score}
+}
+
+func suggestLinkExtractor(items *goquery.Selection) string {
+	candidates := []string{"a[href]"}
+	for _, heading := range []string{"h1", "h2", "h3", "h4", "h5", "h6"} {
+		candidates = append(candidates, heading+" a[href]")
+	}
+	items.First().Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		candidates = append(candidates, nodeSelector(link, true)+"[href]")
+	})
+
+	for _, candidate := range candidates {
+		if uniqueInAll(items, candidate) {
+			return candidate + " | href"
+		}
+	}
+	return ""
+}
+
+func suggestDateExtractor(items *goquery.Selection) string {
+	candidates := []string{}
+	first := items.First()
+	if _, found := first.Find("time").Attr("datetime"); found {
+		candidates = append(candidates, "time | datetime?::time")
+	}
+	candidates = append(candidates, "time | text?::time")
+	first.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Children().Length() == 0 && dateLikeRegexp.MatchString(s.Text()) {
+			candidates = append(candidates, nodeSelector(s, true)+" | text?::time")
+		}
+	})
+
+	for _, candidate := range candidates {
+		spec, err := xt.ParseSpec(candidate)
+		if err != nil || !uniqueInAll(items, spec.Selector) {
+			continue
+		}
+		if extractsFromAll(items, spec.Extractor()) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func suggestImageExtractor(items *goquery.Selection) string {
+	if uniqueInAll(items, "img[src]") {
+		return "img | src?"
+	}
+	return ""
+}
+
+func uniqueInAll(items *goquery.Selection, selector string) bool {
+	unique := true
+	items.EachWithBreak(func(_ int, item *goquery.Selection) bool {
+		unique = item.Find(selector).Length() == 1
+		return unique
+	})
+	return unique
+}
+
+func extractsFromAll(items *goquery.Selection, extractor xt.Extractor) bool {
+	extracted := true
+	items.EachWithBreak(func(_ int, item *goquery.Selection) bool {
+		val, err := extractor.Extract(xt.ExtractorArgs{Root: item})
+		extracted = err == nil && val != nil
+		return extracted
+	})
+	return extracted
+}
+
+// pathSelector builds a selector for sel based on its ancestors, stopping at
+// the first one that has an id.
+func pathSelector(sel *goquery.Selection) string {
+	parts := []string{}
+	for current := sel; current.Length() > 0; current = current.Parent() {
+		name := goquery.NodeName(current)
+		if name == "html" || strings.HasPrefix(name, "#") {
+			break
+		}
+		part := nodeSelector(current, true)
+		parts = append([]string{part}, parts...)
+		if strings.Contains(part, "#") || name == "body" {
+			break
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+func nodeSelector(sel *goquery.Selection, useID bool) string {
+	selector := goquery.NodeName(sel)
+
+	if id, _ := sel.Attr("id"); useID && safeIdentifierRegexp.MatchString(id) {
+		return selector + "#" + id
+	}
+
+	class, _ := sel.Attr("class")
+	classes := strings.Fields(class)
+	sort.Strings(classes)
+	for _, c := range classes {
+		if safeIdentifierRegexp.MatchString(c) {
+			selector += "." + c
+		}
+	}
+	return selector
+}
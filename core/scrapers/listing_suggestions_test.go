@@ -0,0 +1,58 @@
+package scrapers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SuggestListingConfigs", func() {
+	It("ranks repeated structures with article links first", func() {
+		suggestions, err := SuggestListingConfigs(context.Background(), []byte(listingWithMenusHTML), "https://example.com/news")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(suggestions)).To(BeNumerically(">=", 2))
+
+		Expect(suggestions[0].Items).To(Equal(3))
+		Expect(suggestions[0].Config).To(Equal(&ArticleListScraperConfig{
+			LinkContainer:        "div#content > div.news > div.item",
+			URLExtractor:         "h2 a[href] | href",
+			PublishedAtExtractor: "span.date | text?::time",
+			ImageURLExtractor:    "img | src?",
+		}))
+		Expect(suggestions[0].Preview).To(HaveLen(3))
+		Expect(suggestions[0].Preview[0].URL).To(Equal("https://example.com/news/1"))
+		Expect(suggestions[0].Preview[0].PublishedAt).NotTo(BeNil())
+
+		Expect(suggestions[1].Config.LinkContainer).To(Equal("body > nav > ul > li"))
+		Expect(suggestions[1].Score).To(BeNumerically("<", suggestions[0].Score))
+	})
+
+	It("returns nothing when there are no repeated links", func() {
+		suggestions, err := SuggestListingConfigs(context.Background(), []byte(`<p><a href="/a">A</a></p>`), "https://example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suggestions).To(BeEmpty())
+	})
+})
+
+var listingWithMenusHTML = `<html><body>
+	<nav><ul>
+		<li><a href="/">Início</a></li>
+		<li><a href="/sobre">Sobre</a></li>
+		<li><a href="/contato">Contato</a></li>
+	</ul></nav>
+	<div id="content"><div class="news">
+		<div class="item">
+			<h2><a href="/news/1">Governo anuncia novas medidas de combate</a></h2>
+			<span class="date">08/06/2020 23:11</span><a href="/tag/x">tag</a><img src="/1.png">
+		</div>
+		<div class="item">
+			<h2><a href="/news/2">Secretaria divulga boletim epidemiológico</a></h2>
+			<span class="date">07/06/2020 10:00</span><a href="/tag/x">tag</a><img src="/2.png">
+		</div>
+		<div class="item">
+			<h2><a href="/news/3">Hospital de campanha recebe pacientes</a></h2>
+			<span class="date">06/06/2020 09:30</span><a href="/tag/y">tag</a><img src="/3.png">
+		</div>
+	</div></div>
+</body></html>`
@@ -0,0 +1,101 @@
+package scrapers
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/pdf"
+	xt "github.com/fgrehm/brinfo/core/scrapers/extractors"
+)
+
+// DateSourcePDF is the name used for dates found on the metadata of PDF
+// files.
+const DateSourcePDF = "pdf"
+
+// excerptLength is the maximum length of excerpts generated from the text of
+// PDF files that don't have a subject.
+const excerptLength = 300
+
+var (
+	// Office suites set the title to the name of the file when the document
+	// doesn't have one, like "Microsoft Word - boletim 12.docx"
+	pdfTitleProgramRegexp   = regexp.MustCompile(`^(?i)(microsoft (word|excel|powerpoint)|libreoffice|writer)\s+-\s+`)
+	pdfTitleExtensionRegexp = regexp.MustCompile(`(?i)\.(docx?|xlsx?|pptx?|odt|ods|rtf|pdf|txt)$`)
+)
+
+func isPDF(body []byte, httpContentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(httpContentType)
+	if err == nil && mediaType == "application/pdf" {
+		return true
+	}
+	return pdf.IsPDF(body)
+}
+
+// extractPDF reads the title, text and dates of a PDF file into data. The
+// extractors configured are not used since they work on HTML.
+func (s *articleScraper) extractPDF(data *core.ArticleData, body []byte, args xt.ExtractorArgs) (dateCandidates, error) {
+	data.Extra = map[string]interface{}{
+		"pdf": mustGzip(body),
+	}
+
+	doc, err := pdf.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	data.FullText = doc.Text
+	data.Title = pdfTitle(doc)
+	data.Excerpt = doc.Subject
+	if data.Excerpt == "" {
+		data.Excerpt = pdfExcerpt(doc.Text, data.Title)
+	}
+
+	pdfDates := &core.ArticleData{}
+	if doc.CreationDate != "" {
+		if t, precision, err := pdf.ParseDate(doc.CreationDate, args.Location); err == nil {
+			pdfDates.PublishedAt, pdfDates.PublishedAtPrecision = t, precision
+		}
+	}
+	if doc.ModDate != "" {
+		if t, precision, err := pdf.ParseDate(doc.ModDate, args.Location); err == nil {
+			pdfDates.ModifiedAt, pdfDates.ModifiedAtPrecision = t, precision
+		}
+	}
+	return dateCandidates{}.addData(DateSourcePDF, pdfDates), nil
+}
+
+// pdfTitle returns the title set on the metadata of the document, unless it
+// is just a file name, falling back to the first line of its text.
+func pdfTitle(doc *pdf.Document) string {
+	title := pdfTitleProgramRegexp.ReplaceAllString(doc.Title, "")
+	if title != "" && !pdfTitleExtensionRegexp.MatchString(title) {
+		return title
+	}
+	for _, line := range strings.Split(doc.Text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return strings.TrimSpace(pdfTitleExtensionRegexp.ReplaceAllString(title, ""))
+}
+
+// pdfExcerpt returns the beginning of text, skipping the title.
+func pdfExcerpt(text, title string) string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" && line != title {
+			lines = append(lines, line)
+		}
+	}
+	excerpt := strings.Join(lines, " ")
+	if len(excerpt) <= excerptLength {
+		return excerpt
+	}
+	excerpt = excerpt[:excerptLength]
+	if i := strings.LastIndex(excerpt, " "); i > 0 {
+		excerpt = excerpt[:i]
+	}
+	return excerpt + "..."
+}
@@ -0,0 +1,89 @@
+package scrapers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core/dates"
+	. "github.com/fgrehm/brinfo/core/scrapers/extractors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const bulletinPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>
+endobj
+4 0 obj
+<< /Length 0 >>
+stream
+BT /F1 16 Tf 72 760 Td (Boletim Epidemiol\363gico 12) Tj
+/F1 11 Tf 0 -30 Td (O estado registrou 1.234 casos confirmados de COVID-19.) Tj
+0 -14 Td (A taxa de ocupa\347\343o de leitos de UTI \351 de 70%.) Tj ET
+endstream
+endobj
+5 0 obj
+<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>
+endobj
+6 0 obj
+<< /Title (Microsoft Word - boletim-12.docx) /CreationDate (D:20200512103000) /ModDate (D:20200512180000-03'00') >>
+endobj
+trailer
+<< /Root 1 0 R /Info 6 0 R >>
+%%EOF
+`
+
+var _ = Describe("ArticleScraper with PDF files", func() {
+	var (
+		cfg *ArticleScraperConfig
+		ctx context.Context
+		now time.Time
+	)
+
+	brLoc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		panic(err)
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2020, 5, 13, 9, 0, 0, 0, brLoc)
+		cfg = &ArticleScraperConfig{Clock: fakeClock{now}, Extractors: []Extractor{BasicArticle()}}
+		ctx = context.Background()
+	})
+
+	It("extracts the text and dates of PDF files", func() {
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(bulletinPDF), "https://saude.es.gov.br/boletim-12.pdf", "application/pdf")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Boletim Epidemiológico 12"))
+		Expect(data.FullText).To(Equal("Boletim Epidemiológico 12\nO estado registrou 1.234 casos confirmados de COVID-19.\nA taxa de ocupação de leitos de UTI é de 70%."))
+		Expect(data.Excerpt).To(Equal("O estado registrou 1.234 casos confirmados de COVID-19. A taxa de ocupação de leitos de UTI é de 70%."))
+		Expect(data.FullTextHash).NotTo(BeEmpty())
+		Expect(*data.PublishedAt).To(Equal(time.Date(2020, 5, 12, 10, 30, 0, 0, brLoc)))
+		Expect(data.PublishedAtPrecision).To(Equal(dates.PrecisionSecond))
+		Expect(data.ModifiedAt.Equal(time.Date(2020, 5, 12, 18, 0, 0, 0, brLoc))).To(BeTrue())
+		Expect(data.Extra).To(HaveKey("pdf"))
+		Expect(data.Extra).NotTo(HaveKey("html"))
+	})
+
+	It("detects PDF files without a content type", func() {
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(bulletinPDF), "file:///tmp/boletim-12.pdf", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Boletim Epidemiológico 12"))
+	})
+
+	It("uses the title found on the metadata", func() {
+		body := strings.Replace(bulletinPDF, "Microsoft Word - boletim-12.docx", "Boletim 12 - Maio de 2020", 1)
+
+		data, err := NewArticleScraper(cfg).Run(ctx, []byte(body), "https://saude.es.gov.br/boletim-12.pdf", "application/pdf")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Title).To(Equal("Boletim 12 - Maio de 2020"))
+	})
+})
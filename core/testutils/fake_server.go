@@ -1,35 +1,83 @@
 package testutils
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ArticleVariant determines how the article metadata is rendered on its page.
+type ArticleVariant string
+
+const (
+	PlainArticle     ArticleVariant = ""
+	OpenGraphArticle ArticleVariant = "opengraph"
+	JSONLDArticle    ArticleVariant = "json-ld"
+	RNewsArticle     ArticleVariant = "rnews"
 )
 
+// Server is a stand-in for a government portal that serves a list of
+// articles, the articles themselves, RSS and sitemap endpoints.
+//
+// Articles are rendered at /articles/show?id=ID or at the path of their URL.
+// Listings at /articles are paginated according to PerPage. Redirects maps
+// paths to the location they redirect to and StatusCodes maps paths to the
+// status they respond with. Files maps paths to the contents of documents
+// served as is. Responses can be delayed with Delay and encoded with Charset
+// (either "utf-8", "iso-8859-1" or "windows-1252").
 type Server struct {
-	server   *httptest.Server
-	Articles []*Article
-	PerPage  int
+	server      *httptest.Server
+	Articles    []*Article
+	PerPage     int
+	Redirects   map[string]string
+	StatusCodes map[string]int
+	Files       map[string][]byte
+	Delay       time.Duration
+	Charset     string
 }
 
+// Article is an article served by the fake portal. Dates are rendered as is,
+// so they must be in a format supported by the extractors used for the
+// variant being tested.
 type Article struct {
 	ID          string
 	URL         string
 	ImageURL    string
 	Title       string
 	PublishedAt string
+	ModifiedAt  string
 	Excerpt     string
 	Head        string
 	Body        string
+	Variant     ArticleVariant
 }
 
 func NewTestServer() *Server {
-	ts := &Server{PerPage: 5, Articles: []*Article{}}
+	ts := &Server{
+		PerPage:     5,
+		Articles:    []*Article{},
+		Redirects:   map[string]string{},
+		StatusCodes: map[string]int{},
+		Files:       map[string][]byte{},
+	}
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/articles", ts.listArticles)
 	mux.HandleFunc("/articles/show", ts.showArticle)
+	mux.HandleFunc("/rss", ts.rss)
+	mux.HandleFunc("/sitemap.xml", ts.sitemap)
+	mux.HandleFunc("/", ts.showArticleByPath)
 
-	ts.server = httptest.NewServer(mux)
+	ts.server = httptest.NewServer(ts.wrap(mux))
 	return ts
 }
 
@@ -41,33 +89,98 @@ func (s *Server) Close() {
 	s.server.Close()
 }
 
+// ArticleURL returns the absolute URL of an article.
+func (s *Server) ArticleURL(a *Article) string {
+	url := a.URL
+	if url == "" {
+		url = "/articles/show?id=" + a.ID
+	}
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+	return s.URL() + "/" + strings.TrimPrefix(url, "/")
+}
+
+func (s *Server) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Delay > 0 {
+			time.Sleep(s.Delay)
+		}
+		if location, ok := s.Redirects[r.URL.Path]; ok {
+			http.Redirect(w, r, location, http.StatusMovedPermanently)
+			return
+		}
+		if status, ok := s.StatusCodes[r.URL.Path]; ok {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		if file, ok := s.Files[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", http.DetectContentType(file))
+			if _, err := w.Write(file); err != nil {
+				panic(err)
+			}
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) listArticles(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html")
-	_, err := w.Write([]byte(`<!DOCTYPE html>
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	articles := s.Articles
+	perPage := s.PerPage
+	if perPage <= 0 {
+		perPage = len(articles)
+	}
+	start := (page - 1) * perPage
+	if start > len(articles) {
+		start = len(articles)
+	}
+	end := start + perPage
+	if end > len(articles) {
+		end = len(articles)
+	}
+
+	pagination := ""
+	if page > 1 {
+		pagination += fmt.Sprintf(`<a class="previous" href="/articles?page=%d">Anterior</a>`, page-1)
+	}
+	if end < len(articles) {
+		pagination += fmt.Sprintf(`<a class="next" href="/articles?page=%d">Próxima</a>`, page+1)
+	}
+
+	s.writeHTML(w, `<!DOCTYPE html>
 <html>
 	<head>
+		`+s.metaCharset()+`
 		<title>Articles</title>
 	</head>
 	<body>
 		<h1>All articles</h1>
 		<ul>
-			` + s.renderArticlesList() + `
+			`+s.renderArticlesList(articles[start:end])+`
 		</ul>
+		<div class="pagination">`+pagination+`</div>
 	</body>
-</html>`))
-	if err != nil {
-		panic(err)
-	}
+</html>`)
 }
 
-func (s *Server) renderArticlesList() string {
+func (s *Server) renderArticlesList(articles []*Article) string {
 	list := ""
-	for _, a := range s.Articles {
+	for _, a := range articles {
+		url := a.URL
+		if url == "" {
+			url = "/articles/show?id=" + a.ID
+		}
 		linkText := a.Title
 		if linkText == "" {
-			linkText = a.URL
+			linkText = url
 		}
-		list += `<li><a href="` + a.URL + `">` + linkText + `</a>`
+		list += `<li><a href="` + url + `">` + linkText + `</a>`
 		if a.PublishedAt != "" {
 			list += `<time>` + a.PublishedAt + `</time>`
 		}
@@ -81,8 +194,222 @@ func (s *Server) renderArticlesList() string {
 }
 
 func (s *Server) showArticle(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html")
-	panic("BOOOM")
+	id := r.URL.Query().Get("id")
+	for _, a := range s.Articles {
+		if a.ID != "" && a.ID == id {
+			s.writeHTML(w, s.renderArticle(a))
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *Server) showArticleByPath(w http.ResponseWriter, r *http.Request) {
+	for _, a := range s.Articles {
+		u, err := neturl.Parse(s.ArticleURL(a))
+		if err == nil && u.Path == r.URL.Path && u.Path != "/articles/show" {
+			s.writeHTML(w, s.renderArticle(a))
+			return
+		}
+	}
+	http.NotFound(w, r)
 }
 
-// func getArticleByID
+func (s *Server) renderArticle(a *Article) string {
+	head := a.Head
+	body := `<h1>` + html.EscapeString(a.Title) + `</h1>
+		`
+
+	switch a.Variant {
+	case OpenGraphArticle:
+		head += `
+		<meta property="og:type" content="article">
+		<meta property="og:title" content="` + html.EscapeString(a.Title) + `">
+		<meta property="og:description" content="` + html.EscapeString(a.Excerpt) + `">
+		<meta property="og:url" content="` + s.ArticleURL(a) + `">`
+		if a.ImageURL != "" {
+			head += `
+		<meta property="og:image" content="` + a.ImageURL + `">
+		<meta property="og:image:width" content="800">
+		<meta property="og:image:height" content="600">`
+		}
+		if a.PublishedAt != "" {
+			head += `
+		<meta property="article:published_time" content="` + a.PublishedAt + `">`
+		}
+		if a.ModifiedAt != "" {
+			head += `
+		<meta property="article:modified_time" content="` + a.ModifiedAt + `">`
+		}
+		body += `<p>` + a.Body + `</p>`
+
+	case JSONLDArticle:
+		data := map[string]interface{}{
+			"@context":    "https://schema.org",
+			"@type":       "NewsArticle",
+			"headline":    a.Title,
+			"description": a.Excerpt,
+		}
+		if a.ImageURL != "" {
+			data["image"] = []string{a.ImageURL}
+		}
+		if a.PublishedAt != "" {
+			data["datePublished"] = a.PublishedAt
+		}
+		if a.ModifiedAt != "" {
+			data["dateModified"] = a.ModifiedAt
+		}
+		jsonLD, err := json.Marshal(data)
+		if err != nil {
+			panic(err)
+		}
+		head += `
+		<meta name="description" content="` + html.EscapeString(a.Excerpt) + `">
+		<script type="application/ld+json">` + string(jsonLD) + `</script>`
+		body += `<p>` + a.Body + `</p>`
+
+	case RNewsArticle:
+		head += `
+		<meta name="description" content="` + html.EscapeString(a.Excerpt) + `">`
+		body = `<article vocab="http://schema.org/" typeof="Article" prefix="rnews: http://iptc.org/std/rNews/2011-10-07#">` + body
+		if a.PublishedAt != "" {
+			body += `
+			<span class="documentPublished"><span>publicado</span>: <span property="rnews:datePublished">` + a.PublishedAt + `</span></span>`
+		}
+		if a.ModifiedAt != "" {
+			body += `
+			<span class="documentModified"><span>última modificação</span>: <span property="rnews:dateModified">` + a.ModifiedAt + `</span></span>`
+		}
+		body += `<p>` + a.Body + `</p></article>`
+
+	default:
+		head += `
+		<meta name="description" content="` + html.EscapeString(a.Excerpt) + `">`
+		if a.PublishedAt != "" {
+			body += `<time>` + a.PublishedAt + `</time>
+		`
+		}
+		if a.ImageURL != "" {
+			body += `<img src="` + a.ImageURL + `">
+		`
+		}
+		body += `<p>` + a.Body + `</p>`
+	}
+
+	return `<!DOCTYPE html>
+<html>
+	<head>
+		` + s.metaCharset() + `
+		<title>` + html.EscapeString(a.Title) + `</title>` + head + `
+	</head>
+	<body>
+		` + body + `
+	</body>
+</html>`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title string    `xml:"title"`
+	Link  string    `xml:"link"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description,omitempty"`
+	PubDate     string `xml:"pubDate,omitempty"`
+}
+
+func (s *Server) rss(w http.ResponseWriter, r *http.Request) {
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{Title: "Articles", Link: s.URL() + "/articles"}}
+	for _, a := range s.Articles {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       a.Title,
+			Link:        s.ArticleURL(a),
+			GUID:        s.ArticleURL(a),
+			Description: a.Excerpt,
+			PubDate:     a.PublishedAt,
+		})
+	}
+	s.writeXML(w, "application/rss+xml", feed)
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func (s *Server) sitemap(w http.ResponseWriter, r *http.Request) {
+	urlSet := sitemapURLSet{}
+	for _, a := range s.Articles {
+		lastMod := a.ModifiedAt
+		if lastMod == "" {
+			lastMod = a.PublishedAt
+		}
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: s.ArticleURL(a), LastMod: lastMod})
+	}
+	s.writeXML(w, "application/xml", urlSet)
+}
+
+func (s *Server) metaCharset() string {
+	return `<meta charset="` + s.charset() + `">`
+}
+
+func (s *Server) charset() string {
+	if s.Charset == "" {
+		return "utf-8"
+	}
+	return s.Charset
+}
+
+func (s *Server) writeHTML(w http.ResponseWriter, body string) {
+	s.write(w, "text/html", body)
+}
+
+func (s *Server) writeXML(w http.ResponseWriter, contentType string, data interface{}) {
+	out, err := xml.MarshalIndent(data, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	s.write(w, contentType, xml.Header+string(out))
+}
+
+func (s *Server) write(w http.ResponseWriter, contentType, body string) {
+	w.Header().Set("Content-Type", contentType+"; charset="+s.charset())
+
+	encoded := []byte(body)
+	switch strings.ToLower(s.charset()) {
+	case "utf-8":
+	case "iso-8859-1":
+		encoded = mustEncode(charmap.ISO8859_1, body)
+	case "windows-1252":
+		encoded = mustEncode(charmap.Windows1252, body)
+	default:
+		panic("Unsupported charset " + s.Charset)
+	}
+
+	if _, err := w.Write(encoded); err != nil {
+		panic(err)
+	}
+}
+
+func mustEncode(cm *charmap.Charmap, str string) []byte {
+	encoded, err := cm.NewEncoder().String(str)
+	if err != nil {
+		panic(err)
+	}
+	return []byte(encoded)
+}
@@ -0,0 +1,79 @@
+package testutils_test
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/fgrehm/brinfo/core/testutils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var ts *Server
+
+	BeforeEach(func() {
+		ts = NewTestServer()
+		ts.Articles = []*Article{
+			{ID: "1", Title: "First", PublishedAt: "Mon, 15 Jun 2020 19:56:00 -0300"},
+			{URL: "/second", Title: "Second"},
+		}
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(ts.URL() + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	It("paginates the list of articles", func() {
+		ts.PerPage = 1
+
+		status, body := get("/articles")
+		Expect(status).To(Equal(200))
+		Expect(body).To(ContainSubstring(`href="/articles/show?id=1"`))
+		Expect(body).NotTo(ContainSubstring(`href="/second"`))
+		Expect(body).To(ContainSubstring(`href="/articles?page=2"`))
+
+		_, body = get("/articles?page=2")
+		Expect(body).To(ContainSubstring(`href="/second"`))
+		Expect(body).NotTo(ContainSubstring(`class="next"`))
+	})
+
+	It("renders articles by id and by path", func() {
+		status, body := get("/articles/show?id=1")
+		Expect(status).To(Equal(200))
+		Expect(body).To(ContainSubstring("<title>First</title>"))
+
+		status, body = get("/second")
+		Expect(status).To(Equal(200))
+		Expect(body).To(ContainSubstring("<title>Second</title>"))
+
+		status, _ = get("/articles/show?id=404")
+		Expect(status).To(Equal(404))
+	})
+
+	It("serves RSS and sitemap endpoints", func() {
+		_, body := get("/rss")
+		Expect(body).To(ContainSubstring("<link>" + ts.URL() + "/articles/show?id=1</link>"))
+		Expect(body).To(ContainSubstring("<pubDate>Mon, 15 Jun 2020 19:56:00 -0300</pubDate>"))
+
+		_, body = get("/sitemap.xml")
+		Expect(body).To(ContainSubstring("<loc>" + ts.URL() + "/second</loc>"))
+	})
+
+	It("responds with configured status codes", func() {
+		ts.StatusCodes["/second"] = 503
+
+		status, _ := get("/second")
+		Expect(status).To(Equal(503))
+	})
+})
@@ -0,0 +1,47 @@
+package testutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core/fixtures"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// DescribeFixtures declares a spec for each fixture kept on dir that fails
+// with the field level differences found when extractors no longer produce the
+// data that was recorded.
+func DescribeFixtures(dir string) bool {
+	return Describe("Fixtures on "+dir, func() {
+		list, err := fixtures.Load(dir)
+		if err != nil {
+			It("loads fixtures", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			return
+		}
+
+		for _, f := range list {
+			f := f
+			It("extracts the recorded data from "+f.Name, func() {
+				result, err := f.Verify(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Changes).To(BeEmpty(), formatChanges(result))
+			})
+		}
+	})
+}
+
+func formatChanges(result *fixtures.Result) string {
+	lines := []string{fmt.Sprintf("%s (%s) changed:", result.Fixture.Name, result.Fixture.URL)}
+	for _, c := range result.Changes {
+		before, _ := json.Marshal(c.Before)
+		after, _ := json.Marshal(c.After)
+		lines = append(lines, fmt.Sprintf("  %s:\n    - %s\n    + %s", c.Field, before, after))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,7 @@
+package testutils_test
+
+import (
+	"github.com/fgrehm/brinfo/core/testutils"
+)
+
+var _ = testutils.DescribeFixtures("../../fixtures")
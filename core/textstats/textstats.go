@@ -0,0 +1,78 @@
+// Package textstats computes statistics of the text of articles, like their
+// number of words and how long they take to read.
+package textstats
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// WordsPerMinute is the reading speed assumed for news in Portuguese.
+const WordsPerMinute = 200
+
+// abbreviations are followed by a period without ending sentences.
+var abbreviations = map[string]bool{
+	"sr": true, "sra": true, "srs": true, "dr": true, "dra": true, "prof": true, "profa": true,
+	"art": true, "arts": true, "inc": true, "n": true, "nº": true, "p": true, "pág": true,
+	"av": true, "gov": true, "dep": true, "exmo": true, "exma": true, "cel": true, "sgt": true,
+}
+
+type Stats struct {
+	Words     int
+	Sentences int
+	// ReadingMinutes is rounded up, texts with any words take at least a
+	// minute to read.
+	ReadingMinutes int
+}
+
+// Compute returns the statistics of text, which is expected to have a line
+// for each paragraph like the full text of articles. Lines without
+// punctuation at the end, like headings and list items, count as sentences.
+func Compute(text string) Stats {
+	stats := Stats{}
+	for _, line := range strings.Split(text, "\n") {
+		inSentence := false
+		for _, token := range strings.Fields(line) {
+			if !hasWordChars(token) {
+				continue
+			}
+			stats.Words++
+			inSentence = true
+			if endsSentence(token) {
+				stats.Sentences++
+				inSentence = false
+			}
+		}
+		if inSentence {
+			stats.Sentences++
+		}
+	}
+	if stats.Words > 0 {
+		stats.ReadingMinutes = int(math.Ceil(float64(stats.Words) / WordsPerMinute))
+	}
+	return stats
+}
+
+func hasWordChars(token string) bool {
+	for _, r := range token {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsSentence(token string) bool {
+	trimmed := strings.TrimRight(token, `"'”’)»`)
+	switch {
+	case strings.HasSuffix(trimmed, "!"), strings.HasSuffix(trimmed, "?"), strings.HasSuffix(trimmed, "…"):
+		return true
+	case strings.HasSuffix(trimmed, "."):
+		word := strings.TrimLeftFunc(strings.TrimRight(trimmed, "."), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		return strings.HasSuffix(trimmed, "..") || !abbreviations[strings.ToLower(word)]
+	}
+	return false
+}
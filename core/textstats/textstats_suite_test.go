@@ -0,0 +1,13 @@
+package textstats_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTextStats(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Text Stats Suite")
+}
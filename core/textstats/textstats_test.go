@@ -0,0 +1,33 @@
+package textstats_test
+
+import (
+	"strings"
+
+	. "github.com/fgrehm/brinfo/core/textstats"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compute", func() {
+	It("counts words and sentences", func() {
+		text := "Vacinação nas escolas\n" +
+			"A campanha começa na segunda-feira (12). Segundo o Sr. João, secretário da pasta, serão R$ 1.500,00 investidos!\n" +
+			"Quem pode se vacinar? Estudantes e professores…"
+		Expect(Compute(text)).To(Equal(Stats{Words: 27, Sentences: 5, ReadingMinutes: 1}))
+	})
+
+	It("counts quoted sentences", func() {
+		Expect(Compute(`Ele disse: "Vamos vacinar todos." Depois saiu.`).Sentences).To(Equal(2))
+	})
+
+	It("rounds the reading time up", func() {
+		Expect(Compute(strings.Repeat("palavra ", 201)).ReadingMinutes).To(Equal(2))
+		Expect(Compute(strings.Repeat("palavra ", 200)).ReadingMinutes).To(Equal(1))
+	})
+
+	It("is empty for texts without words", func() {
+		Expect(Compute("")).To(Equal(Stats{}))
+		Expect(Compute(" - \n ... ")).To(Equal(Stats{}))
+	})
+})
@@ -0,0 +1,192 @@
+// Package urls normalizes the URLs of articles so that the different ways a
+// page is linked to, like with tracking parameters or with and without www,
+// can be identified as the same article.
+package urls
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var ErrNotAbsolute = errors.New("URL is not absolute")
+
+// trackingParams are query parameters added by campaigns and social networks
+// that never change the page being linked to. Parameters starting with the
+// prefixes in trackingParamPrefixes are dropped as well.
+var (
+	trackingParams = map[string]bool{
+		"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+		"igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true,
+		"__twitter_impression": true,
+	}
+	trackingParamPrefixes = []string{"utm_", "pk_", "mtm_"}
+)
+
+// Rule customizes how the URLs of a source are normalized.
+type Rule struct {
+	// Host limits the rule to a host and its subdomains, rules without a host
+	// apply to all URLs.
+	Host string `json:"host,omitempty"`
+	// KeepParams are the only query parameters kept, for sources that identify
+	// articles by some parameter like `?id=123` and add others freely.
+	KeepParams []string `json:"keep_params,omitempty"`
+	// DropParams are query parameters dropped along with the tracking ones.
+	DropParams []string `json:"drop_params,omitempty"`
+	// TrimSuffixes are removed from the end of paths, like the ones used for
+	// alternative views of the same article.
+	TrimSuffixes []string `json:"trim_suffixes,omitempty"`
+}
+
+// DefaultRules are applied before the rules of each source.
+var DefaultRules = []Rule{
+	// Pages served by the AMP plugin of WordPress
+	{TrimSuffixes: []string{"/amp"}},
+	// The Plone portal of the federal government shows the same content on the
+	// default view of news items
+	{Host: "www.gov.br", TrimSuffixes: []string{"/view"}},
+}
+
+func (r Rule) appliesTo(host string) bool {
+	return r.Host == "" || host == r.Host || strings.HasSuffix(host, "."+r.Host)
+}
+
+// Normalize returns rawURL with changes that don't affect the page it points
+// to: lowercase scheme and host, no default port, no fragment, no tracking
+// parameters and the remaining parameters sorted. DefaultRules and rules are
+// applied on top of that.
+func Normalize(rawURL string, rules []Rule) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return "", ErrNotAbsolute
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host += ":" + port
+	}
+	u.Fragment = ""
+	u.User = nil
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	applicable := []Rule{}
+	for _, r := range append(append([]Rule{}, DefaultRules...), rules...) {
+		if r.appliesTo(host) {
+			applicable = append(applicable, r)
+		}
+	}
+
+	for _, r := range applicable {
+		for _, suffix := range r.TrimSuffixes {
+			trimmed := strings.TrimSuffix(u.Path, "/")
+			if strings.HasSuffix(trimmed, suffix) && trimmed != suffix {
+				u.Path = strings.TrimSuffix(trimmed, suffix)
+				u.RawPath = ""
+			}
+		}
+	}
+
+	u.RawQuery = normalizeQuery(u.Query(), applicable)
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+func normalizeQuery(query url.Values, rules []Rule) string {
+	keep := map[string]bool{}
+	drop := map[string]bool{}
+	for _, r := range rules {
+		for _, p := range r.KeepParams {
+			keep[p] = true
+		}
+		for _, p := range r.DropParams {
+			drop[p] = true
+		}
+	}
+
+	for name := range query {
+		if isTrackingParam(name) || drop[name] || (len(keep) > 0 && !keep[name]) {
+			query.Del(name)
+		}
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return query.Encode()
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	if trackingParams[name] {
+		return true
+	}
+	for _, prefix := range trackingParamPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns the identity of the article found at rawURL, which is its
+// normalized URL without the scheme, the www prefix and trailing slashes.
+// It is meant for comparing and hashing URLs, not for fetching them.
+func Key(rawURL string, rules []Rule) (string, error) {
+	normalized, err := Normalize(rawURL, rules)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.TrimPrefix(u.Host, "www.") + strings.TrimRight(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key, nil
+}
+
+// Canonical returns the normalized URL that identifies the page fetched from
+// pageURL. The canonical URL declared by the page, through rel=canonical or
+// og:url, is used when it is on the same site. Declarations pointing to the
+// home page from other pages are ignored since some sites use the same tags on
+// every page.
+func Canonical(pageURL, declared string, rules []Rule) (string, error) {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	declared = strings.TrimSpace(declared)
+	if declared != "" {
+		if ref, err := url.Parse(declared); err == nil {
+			canonical := page.ResolveReference(ref)
+			if sameSite(page, canonical) && !(isHome(canonical) && !isHome(page)) {
+				return Normalize(canonical.String(), rules)
+			}
+		}
+	}
+	return Normalize(pageURL, rules)
+}
+
+func sameSite(a, b *url.URL) bool {
+	return strings.TrimPrefix(strings.ToLower(a.Hostname()), "www.") ==
+		strings.TrimPrefix(strings.ToLower(b.Hostname()), "www.")
+}
+
+func isHome(u *url.URL) bool {
+	return strings.Trim(u.Path, "/") == "" && u.RawQuery == ""
+}
@@ -0,0 +1,13 @@
+package urls_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestURLs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "URLs Suite")
+}
@@ -0,0 +1,95 @@
+package urls_test
+
+import (
+	. "github.com/fgrehm/brinfo/core/urls"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalize", func() {
+	cases := []struct {
+		url        string
+		normalized string
+	}{
+		{"https://www.es.gov.br/noticias/vacina", "https://www.es.gov.br/noticias/vacina"},
+		{"HTTPS://WWW.ES.GOV.BR/noticias/Vacina", "https://www.es.gov.br/noticias/Vacina"},
+		{"https://www.es.gov.br:443/noticias/vacina#comentarios", "https://www.es.gov.br/noticias/vacina"},
+		{"http://www.es.gov.br:8080/noticias/vacina", "http://www.es.gov.br:8080/noticias/vacina"},
+		{"https://www.es.gov.br", "https://www.es.gov.br/"},
+		{"https://www.es.gov.br/noticias/vacina?utm_source=facebook&utm_medium=social&fbclid=abc", "https://www.es.gov.br/noticias/vacina"},
+		{"https://www.es.gov.br/noticia?id=12&page=2&gclid=x", "https://www.es.gov.br/noticia?id=12&page=2"},
+		{"https://www.es.gov.br/noticia?page=2&id=12", "https://www.es.gov.br/noticia?id=12&page=2"},
+		{"https://www.es.gov.br/noticias/vacina/amp/", "https://www.es.gov.br/noticias/vacina"},
+		{"https://www.gov.br/saude/pt-br/noticias/boletim/view", "https://www.gov.br/saude/pt-br/noticias/boletim"},
+		{"https://www.es.gov.br/noticias/view", "https://www.es.gov.br/noticias/view"},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("normalizes "+c.url, func() {
+			Expect(Normalize(c.url, nil)).To(Equal(c.normalized))
+		})
+	}
+
+	It("applies the rules of the host", func() {
+		rules := []Rule{
+			{Host: "ac.gov.br", KeepParams: []string{"id"}},
+			{Host: "es.gov.br", DropParams: []string{"origem"}},
+		}
+
+		Expect(Normalize("https://agencia.ac.gov.br/noticia.php?id=12&sessao=abc", rules)).To(Equal("https://agencia.ac.gov.br/noticia.php?id=12"))
+		Expect(Normalize("https://www.es.gov.br/noticia?origem=home&id=12", rules)).To(Equal("https://www.es.gov.br/noticia?id=12"))
+		Expect(Normalize("https://www.gov.br/noticia?sessao=abc&origem=home", rules)).To(Equal("https://www.gov.br/noticia?origem=home&sessao=abc"))
+	})
+
+	It("fails for relative URLs", func() {
+		_, err := Normalize("/noticias/vacina", nil)
+		Expect(err).To(Equal(ErrNotAbsolute))
+	})
+})
+
+var _ = Describe("Key", func() {
+	It("is the same for the different ways an article is linked to", func() {
+		variants := []string{
+			"https://www.es.gov.br/noticias/vacina",
+			"http://www.es.gov.br/noticias/vacina/",
+			"https://es.gov.br/noticias/vacina?utm_source=twitter",
+			"https://www.es.gov.br/noticias/vacina#topo",
+		}
+		for _, v := range variants {
+			Expect(Key(v, nil)).To(Equal("es.gov.br/noticias/vacina"), v)
+		}
+	})
+
+	It("keeps the query", func() {
+		Expect(Key("https://www.es.gov.br/noticia/?id=12", nil)).To(Equal("es.gov.br/noticia?id=12"))
+	})
+})
+
+var _ = Describe("Canonical", func() {
+	pageURL := "https://www.es.gov.br/noticias/vacina?utm_source=facebook"
+
+	It("uses the URL declared by the page", func() {
+		Expect(Canonical(pageURL, "https://www.es.gov.br/noticias/estado-inicia-vacinacao", nil)).
+			To(Equal("https://www.es.gov.br/noticias/estado-inicia-vacinacao"))
+		Expect(Canonical(pageURL, "/noticias/estado-inicia-vacinacao", nil)).
+			To(Equal("https://www.es.gov.br/noticias/estado-inicia-vacinacao"))
+		Expect(Canonical(pageURL, "http://es.gov.br/noticias/estado-inicia-vacinacao", nil)).
+			To(Equal("http://es.gov.br/noticias/estado-inicia-vacinacao"))
+	})
+
+	It("falls back to the URL of the page", func() {
+		Expect(Canonical(pageURL, "", nil)).To(Equal("https://www.es.gov.br/noticias/vacina"))
+	})
+
+	It("ignores URLs on other sites", func() {
+		Expect(Canonical(pageURL, "https://www.saude.es.gov.br/noticias/vacina", nil)).
+			To(Equal("https://www.es.gov.br/noticias/vacina"))
+	})
+
+	It("ignores the home page", func() {
+		Expect(Canonical(pageURL, "https://www.es.gov.br/", nil)).To(Equal("https://www.es.gov.br/noticias/vacina"))
+		Expect(Canonical("https://www.es.gov.br", "https://www.es.gov.br/", nil)).To(Equal("https://www.es.gov.br/"))
+	})
+})
@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fgrehm/brinfo/core"
+	"github.com/fgrehm/brinfo/core/textstats"
+)
+
+// futureTolerance accounts for sources that publish with wrong timezones.
+const futureTolerance = 12 * time.Hour
+
+// DefaultRules are the checks articles go through before being ingested.
+var DefaultRules = []*Rule{
+	{Name: "url", Severity: SeverityError, Check: checkURL},
+	{Name: "url_hash", Severity: SeverityError, Check: required("url_hash", func(d *core.ArticleData) bool { return d.URLHash != "" })},
+	{Name: "title", Severity: SeverityError, Check: required("title", func(d *core.ArticleData) bool { return d.Title != "" })},
+	{Name: "full_text", Severity: SeverityError, Check: required("full_text", func(d *core.ArticleData) bool { return d.FullText != "" })},
+	{Name: "full_text_hash", Severity: SeverityError, Check: required("full_text_hash", func(d *core.ArticleData) bool { return d.FullTextHash != "" })},
+	{Name: "found_at", Severity: SeverityError, Check: required("found_at", func(d *core.ArticleData) bool { return !d.FoundAt.IsZero() })},
+	{Name: "published_at", Severity: SeverityError, Check: checkPublishedAt},
+	{Name: "updated_at", Severity: SeverityError, Check: checkModifiedAt},
+	{Name: "page_type", Severity: SeverityError, Check: checkPageType(core.PageTypeError, core.PageTypeListing)},
+	{Name: "page_type_other", Severity: SeverityWarning, Check: checkPageType(core.PageTypeOther)},
+	{Name: "image_url", Severity: SeverityError, Check: checkImageURL},
+	{Name: "image_present", Severity: SeverityWarning, Check: checkImagePresent},
+	{Name: "full_text_length", Severity: SeverityWarning, Check: checkFullTextLength},
+	{Name: "title_not_site_name", Severity: SeverityWarning, Check: checkTitleNotSiteName},
+	{Name: "excerpt_not_text_start", Severity: SeverityWarning, Check: checkExcerptNotTextStart},
+	{Name: "language", Severity: SeverityWarning, Check: checkLanguage},
+}
+
+func required(field string, present func(d *core.ArticleData) bool) func(*core.ArticleData, *Config) []string {
+	return func(d *core.ArticleData, _ *Config) []string {
+		if present(d) {
+			return nil
+		}
+		return []string{"missing " + field}
+	}
+}
+
+func checkURL(d *core.ArticleData, _ *Config) []string {
+	if d.URL == "" {
+		return []string{"missing url"}
+	}
+	return checkAbsolute("url", d.URL)
+}
+
+func checkAbsolute(field, rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return []string{fmt.Sprintf("%s is invalid: %s", field, err)}
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return []string{field + " is not absolute"}
+	}
+	return nil
+}
+
+func checkPublishedAt(d *core.ArticleData, _ *Config) []string {
+	if d.PublishedAt == nil || d.PublishedAt.IsZero() {
+		return []string{"missing published_at"}
+	}
+	if inFuture(*d.PublishedAt) {
+		return []string{"published_at in the future"}
+	}
+	return nil
+}
+
+func checkModifiedAt(d *core.ArticleData, _ *Config) []string {
+	if d.ModifiedAt == nil {
+		return nil
+	}
+	if d.ModifiedAt.IsZero() {
+		return []string{"updated_at is set to the zero time"}
+	}
+
+	problems := []string{}
+	if inFuture(*d.ModifiedAt) {
+		problems = append(problems, "updated_at in the future")
+	}
+	if d.PublishedAt != nil && !d.PublishedAt.IsZero() && d.ModifiedAt.Before(*d.PublishedAt) {
+		problems = append(problems, "updated_at before published_at")
+	}
+	return problems
+}
+
+func inFuture(t time.Time) bool {
+	return time.Until(t) >= futureTolerance
+}
+
+// checkPageType reports pages that were classified by the scraper with one of
+// pageTypes. Error pages and listings are never articles, while pages
+// classified as other are often short notes and are only warned about. Pages
+// that were not classified at all, like PDFs, pass.
+func checkPageType(pageTypes ...string) func(*core.ArticleData, *Config) []string {
+	return func(d *core.ArticleData, _ *Config) []string {
+		pageType, _ := d.Extra["page_type"].(string)
+		for _, t := range pageTypes {
+			if pageType == t {
+				return []string{pageTypeMessage(d, pageType)}
+			}
+		}
+		return nil
+	}
+}
+
+func pageTypeMessage(d *core.ArticleData, pageType string) string {
+	msg := "page is not an article, it was classified as " + pageType
+	switch reasons := d.Extra["page_type_reasons"].(type) {
+	case []string:
+		msg += " (" + strings.Join(reasons, ", ") + ")"
+	case []interface{}:
+		// Data loaded from JSON
+		strs := []string{}
+		for _, r := range reasons {
+			strs = append(strs, fmt.Sprint(r))
+		}
+		msg += " (" + strings.Join(strs, ", ") + ")"
+	}
+	return msg
+}
+
+func checkImageURL(d *core.ArticleData, _ *Config) []string {
+	if d.ImageURL == "" {
+		return nil
+	}
+	return checkAbsolute("image_url", d.ImageURL)
+}
+
+// checkImagePresent only reports missing images for sources that require them
+// since many articles don't have any.
+func checkImagePresent(d *core.ArticleData, config *Config) []string {
+	if d.ImageURL != "" || !config.RequireImage {
+		return nil
+	}
+	return []string{"missing image_url"}
+}
+
+func checkFullTextLength(d *core.ArticleData, config *Config) []string {
+	// Missing texts are reported by the full_text rule
+	if config.MinFullTextLength == 0 || d.FullText == "" {
+		return nil
+	}
+	words := d.WordCount
+	if words == 0 {
+		// Data scraped before text statistics were computed
+		words = textstats.Compute(d.FullText).Words
+	}
+	if words < config.MinFullTextLength {
+		return []string{fmt.Sprintf("full_text has %d words, expected at least %d", words, config.MinFullTextLength)}
+	}
+	return nil
+}
+
+func checkTitleNotSiteName(d *core.ArticleData, config *Config) []string {
+	if config.SiteName == "" || d.Title == "" {
+		return nil
+	}
+	if strings.EqualFold(collapseSpaces(d.Title), collapseSpaces(config.SiteName)) {
+		return []string{"title is the name of the site"}
+	}
+	return nil
+}
+
+// checkExcerptNotTextStart catches excerpts that were made up from the first
+// words of the text by the source, which add nothing to the article.
+func checkExcerptNotTextStart(d *core.ArticleData, _ *Config) []string {
+	excerpt := collapseSpaces(d.Excerpt)
+	for _, ellipsis := range []string{"[...]", "[…]", "...", "…"} {
+		excerpt = strings.TrimSpace(strings.TrimSuffix(excerpt, ellipsis))
+	}
+	if excerpt == "" || d.FullText == "" {
+		return nil
+	}
+	if strings.HasPrefix(collapseSpaces(d.FullText), excerpt) {
+		return []string{"excerpt is the start of full_text"}
+	}
+	return nil
+}
+
+func checkLanguage(d *core.ArticleData, config *Config) []string {
+	if len(config.Languages) == 0 || d.Language == "" {
+		return nil
+	}
+	for _, lang := range config.Languages {
+		if lang == d.Language {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("language is %s, expected %s", d.Language, strings.Join(config.Languages, " or "))}
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
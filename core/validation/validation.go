@@ -0,0 +1,152 @@
+// Package validation checks if the data scraped from articles is good enough
+// to be ingested. Checks are named rules with a severity, so that sources can
+// tune the ones that don't fit them and callers get results they can act on
+// instead of a list of messages.
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fgrehm/brinfo/core"
+)
+
+// Severity tells what a rule failing means, articles with errors should not
+// be ingested while warnings point to data that is likely wrong.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	// SeverityOff disables a rule when used on Config.Severities.
+	SeverityOff Severity = "off"
+)
+
+var Severities = []Severity{SeverityError, SeverityWarning, SeverityOff}
+
+// Rule is a named check, Check returns a message for each problem found on
+// the article and nothing when it passes.
+type Rule struct {
+	Name     string
+	Severity Severity
+	Check    func(d *core.ArticleData, config *Config) []string
+}
+
+// Config customizes the rules for a source.
+type Config struct {
+	// Severities overrides the severity of rules by name.
+	Severities map[string]Severity `json:"severities,omitempty"`
+	// RequireImage is for sources that have images on all of their articles,
+	// so that missing ones are reported.
+	RequireImage bool `json:"require_image,omitempty"`
+	// MinFullTextLength is the minimum number of words expected on the full
+	// text, shorter texts are usually extraction failures.
+	MinFullTextLength int `json:"min_full_text_length,omitempty"`
+	// SiteName is checked against titles, which some sources have on every
+	// page instead of the title of the article.
+	SiteName string `json:"site_name,omitempty"`
+	// Languages are the ones the source publishes in, articles detected in
+	// other languages are reported.
+	Languages []string `json:"languages,omitempty"`
+}
+
+// Result is a problem found by a rule.
+type Result struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%s: %s (%s)", r.Severity, r.Message, r.Rule)
+}
+
+// ParseConfig loads a Config from JSON, checking the rules and severities
+// set on it.
+func ParseConfig(data []byte, rules []*Rule) (*Config, error) {
+	config := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	for name, severity := range config.Severities {
+		if findRule(rules, name) == nil {
+			return nil, fmt.Errorf("unknown validation rule '%s'", name)
+		}
+		if !validSeverity(severity) {
+			return nil, fmt.Errorf("unknown severity '%s' for rule '%s', expected one of %s", severity, name, joinSeverities())
+		}
+	}
+	if config.MinFullTextLength < 0 {
+		return nil, fmt.Errorf("min_full_text_length must not be negative, got %d", config.MinFullTextLength)
+	}
+	return config, nil
+}
+
+// Validate runs DefaultRules against d.
+func Validate(d *core.ArticleData, config *Config) []*Result {
+	return Run(d, DefaultRules, config)
+}
+
+// Run checks d against rules, returning the problems found in the order of
+// the rules. A nil config uses the defaults of each rule.
+func Run(d *core.ArticleData, rules []*Rule, config *Config) []*Result {
+	if config == nil {
+		config = &Config{}
+	}
+
+	results := []*Result{}
+	for _, r := range rules {
+		severity := r.Severity
+		if s, ok := config.Severities[r.Name]; ok {
+			severity = s
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		for _, msg := range r.Check(d, config) {
+			results = append(results, &Result{Rule: r.Name, Severity: severity, Message: msg})
+		}
+	}
+	return results
+}
+
+// Errors returns the results that prevent an article from being ingested.
+func Errors(results []*Result) []*Result {
+	errors := []*Result{}
+	for _, r := range results {
+		if r.Severity == SeverityError {
+			errors = append(errors, r)
+		}
+	}
+	return errors
+}
+
+func findRule(rules []*Rule, name string) *Rule {
+	for _, r := range rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func validSeverity(severity Severity) bool {
+	for _, s := range Severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func joinSeverities() string {
+	names := []string{}
+	for _, s := range Severities {
+		names = append(names, string(s))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,283 @@
+package validation_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gomegat "github.com/onsi/gomega/types"
+
+	"github.com/fgrehm/brinfo/core"
+	. "github.com/fgrehm/brinfo/core/validation"
+)
+
+var _ = Describe("Validate", func() {
+	var (
+		data   *core.ArticleData
+		config *Config
+	)
+
+	BeforeEach(func() {
+		now := time.Now()
+		data = &core.ArticleData{
+			URL:          "https://example.com",
+			URLHash:      "url-hash",
+			Title:        "Article title",
+			FullText:     "text",
+			FullTextHash: "text-hash",
+			FoundAt:      now,
+			PublishedAt:  &now,
+			ModifiedAt:   &now,
+			ImageURL:     "http://image.url",
+		}
+		config = &Config{}
+
+		Expect(Validate(data, config)).To(BeEmpty())
+	})
+
+	It("is invalid if no URL is set", func() {
+		data.URL = ""
+		Expect(Validate(data, config)).To(HaveProblem("url", SeverityError, "missing url"))
+	})
+
+	It("is invalid if the URL is not absolute", func() {
+		data.URL = "/noticias/1"
+		Expect(Validate(data, config)).To(HaveProblem("url", SeverityError, "url is not absolute"))
+	})
+
+	It("is invalid if no URLHash is set", func() {
+		data.URLHash = ""
+		Expect(Validate(data, config)).To(HaveProblem("url_hash", SeverityError, "missing url_hash"))
+	})
+
+	It("is invalid if no Title is set", func() {
+		data.Title = ""
+		Expect(Validate(data, config)).To(HaveProblem("title", SeverityError, "missing title"))
+	})
+
+	It("is invalid if no FullText is set", func() {
+		data.FullText = ""
+		Expect(Validate(data, config)).To(HaveProblem("full_text", SeverityError, "missing full_text"))
+	})
+
+	It("is invalid if no FullTextHash is set", func() {
+		data.FullTextHash = ""
+		Expect(Validate(data, config)).To(HaveProblem("full_text_hash", SeverityError, "missing full_text_hash"))
+	})
+
+	It("is invalid if no FoundAt is set", func() {
+		var def time.Time
+		data.FoundAt = def
+		Expect(Validate(data, config)).To(HaveProblem("found_at", SeverityError, "missing found_at"))
+	})
+
+	It("is invalid if no PublishedAt is set", func() {
+		data.PublishedAt = nil
+		Expect(Validate(data, config)).To(HaveProblem("published_at", SeverityError, "missing published_at"))
+
+		var def time.Time
+		data.PublishedAt = &def
+		Expect(Validate(data, config)).To(HaveProblem("published_at", SeverityError, "missing published_at"))
+	})
+
+	It("is invalid if PublishedAt is in the future", func() {
+		newTime := data.PublishedAt.Add(time.Hour * 24)
+		data.PublishedAt = &newTime
+		Expect(Validate(data, config)).To(HaveProblem("published_at", SeverityError, "published_at in the future"))
+	})
+
+	It("is valid if no Excerpt is set", func() {
+		data.Excerpt = ""
+		Expect(Validate(data, config)).To(BeEmpty())
+	})
+
+	It("is invalid if ModifiedAt is set to default", func() {
+		var def time.Time
+		data.ModifiedAt = &def
+		Expect(Validate(data, config)).To(ConsistOf(
+			&Result{Rule: "updated_at", Severity: SeverityError, Message: "updated_at is set to the zero time"},
+		))
+	})
+
+	It("is invalid if ModifiedAt is in the future", func() {
+		newTime := data.PublishedAt.Add(time.Hour * 24)
+		data.ModifiedAt = &newTime
+		Expect(Validate(data, config)).To(HaveProblem("updated_at", SeverityError, "updated_at in the future"))
+	})
+
+	It("is invalid if ModifiedAt is before PublishedAt", func() {
+		newTime := data.PublishedAt.Add(-time.Hour)
+		data.ModifiedAt = &newTime
+		Expect(Validate(data, config)).To(HaveProblem("updated_at", SeverityError, "updated_at before published_at"))
+	})
+
+	It("is valid if no ModifiedAt is set", func() {
+		data.ModifiedAt = nil
+		Expect(Validate(data, config)).To(BeEmpty())
+	})
+
+	It("is invalid if the page is not an article", func() {
+		data.Extra = map[string]interface{}{"page_type": core.PageTypeArticle}
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		data.Extra = map[string]interface{}{"page_type": core.PageTypeError, "page_type_reasons": []string{"login form"}}
+		Expect(Validate(data, config)).To(HaveProblem("page_type", SeverityError, "page is not an article, it was classified as error (login form)"))
+
+		data.Extra = map[string]interface{}{"page_type": core.PageTypeListing, "page_type_reasons": []interface{}{"most of the content is links"}}
+		Expect(Validate(data, config)).To(HaveProblem("page_type", SeverityError, "page is not an article, it was classified as listing (most of the content is links)"))
+	})
+
+	It("warns if the page was classified as other", func() {
+		data.Extra = map[string]interface{}{"page_type": core.PageTypeOther, "page_type_reasons": []string{"text is too short"}}
+		Expect(Validate(data, config)).To(ConsistOf(
+			&Result{Rule: "page_type_other", Severity: SeverityWarning, Message: "page is not an article, it was classified as other (text is too short)"},
+		))
+	})
+
+	It("warns if no ImageURL is set for sources that require images", func() {
+		data.ImageURL = ""
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		config.RequireImage = true
+		Expect(Validate(data, config)).To(ConsistOf(
+			&Result{Rule: "image_present", Severity: SeverityWarning, Message: "missing image_url"},
+		))
+	})
+
+	It("is invalid if ImageURL is relative", func() {
+		data.ImageURL = "/foo/bar"
+		Expect(Validate(data, config)).To(HaveProblem("image_url", SeverityError, "image_url is not absolute"))
+
+		data.ImageURL = "fooo.com/foo/bar"
+		Expect(Validate(data, config)).To(HaveProblem("image_url", SeverityError, "image_url is not absolute"))
+	})
+
+	It("doesn't panic on ImageURLs that can't be parsed", func() {
+		data.ImageURL = "http://[::1"
+		Expect(Validate(data, config)).To(HaveProblem("image_url", SeverityError, `image_url is invalid: parse "http://[::1": missing ']' in host`))
+	})
+
+	It("warns about short texts when a minimum length is configured", func() {
+		data.FullText = "Inscrições abertas"
+		data.WordCount = 2
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		config.MinFullTextLength = 3
+		Expect(Validate(data, config)).To(HaveProblem("full_text_length", SeverityWarning, "full_text has 2 words, expected at least 3"))
+
+		data.FullText = "Inscrições abertas até sexta"
+		data.WordCount = 4
+		Expect(Validate(data, config)).To(BeEmpty())
+	})
+
+	It("counts the words of texts scraped without statistics", func() {
+		data.FullText = "Inscrições abertas"
+		config.MinFullTextLength = 3
+		Expect(Validate(data, config)).To(HaveProblem("full_text_length", SeverityWarning, "full_text has 2 words, expected at least 3"))
+	})
+
+	It("warns about titles that are the name of the site", func() {
+		data.Title = "Governo do  Estado do Espírito Santo"
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		config.SiteName = "Governo do Estado do Espírito Santo"
+		Expect(Validate(data, config)).To(HaveProblem("title_not_site_name", SeverityWarning, "title is the name of the site"))
+	})
+
+	It("warns about excerpts that are the start of the text", func() {
+		data.FullText = "A Secretaria da Educação abriu as inscrições para o processo seletivo de professores."
+		data.Excerpt = "A Secretaria da Educação abriu as inscrições [...]"
+		Expect(Validate(data, config)).To(HaveProblem("excerpt_not_text_start", SeverityWarning, "excerpt is the start of full_text"))
+
+		data.Excerpt = "Processo seletivo tem vagas em todas as regiões"
+		Expect(Validate(data, config)).To(BeEmpty())
+	})
+
+	It("warns about languages the source doesn't publish in", func() {
+		data.Language = "es"
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		config.Languages = []string{"pt", "es"}
+		Expect(Validate(data, config)).To(BeEmpty())
+
+		data.Language = "und"
+		Expect(Validate(data, config)).To(HaveProblem("language", SeverityWarning, "language is und, expected pt or es"))
+	})
+
+	It("uses the severities configured", func() {
+		data.ImageURL = ""
+		data.URLHash = ""
+		config.RequireImage = true
+		config.Severities = map[string]Severity{"image_present": SeverityError, "url_hash": SeverityOff}
+		Expect(Validate(data, config)).To(ConsistOf(
+			&Result{Rule: "image_present", Severity: SeverityError, Message: "missing image_url"},
+		))
+	})
+
+	It("uses the defaults without a config", func() {
+		data.ImageURL = ""
+		Expect(Validate(data, nil)).To(BeEmpty())
+
+		data.Title = ""
+		Expect(Validate(data, nil)).To(HaveProblem("title", SeverityError, "missing title"))
+	})
+})
+
+var _ = Describe("Run", func() {
+	It("runs custom rules", func() {
+		rules := append([]*Rule{{
+			Name:     "gov_br",
+			Severity: SeverityWarning,
+			Check: func(d *core.ArticleData, _ *Config) []string {
+				return []string{"not on gov.br: " + d.URL}
+			},
+		}}, DefaultRules...)
+
+		results := Run(&core.ArticleData{URL: "https://example.com"}, rules, nil)
+		Expect(results[0]).To(Equal(&Result{Rule: "gov_br", Severity: SeverityWarning, Message: "not on gov.br: https://example.com"}))
+		Expect(results).To(HaveProblem("title", SeverityError, "missing title"))
+	})
+})
+
+var _ = Describe("Errors", func() {
+	It("returns the results with errors", func() {
+		results := []*Result{
+			{Rule: "image_present", Severity: SeverityWarning, Message: "missing image_url"},
+			{Rule: "title", Severity: SeverityError, Message: "missing title"},
+		}
+		Expect(Errors(results)).To(Equal(results[1:]))
+		Expect(Errors(results[:1])).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ParseConfig", func() {
+	It("loads configs from JSON", func() {
+		config, err := ParseConfig([]byte(`{"severities": {"image_present": "error"}, "require_image": true, "min_full_text_length": 200, "site_name": "Portal", "languages": ["pt"]}`), DefaultRules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(&Config{
+			Severities:        map[string]Severity{"image_present": SeverityError},
+			RequireImage:      true,
+			MinFullTextLength: 200,
+			SiteName:          "Portal",
+			Languages:         []string{"pt"},
+		}))
+	})
+
+	for json, msg := range map[string]string{
+		`{"severities": {"images": "off"}}`:  "unknown validation rule 'images'",
+		`{"severities": {"title": "fatal"}}`: "unknown severity 'fatal' for rule 'title', expected one of error, warning, off",
+		`{"min_full_text_length": -1}`:       "min_full_text_length must not be negative, got -1",
+		`{"require_images": true}`:           `json: unknown field "require_images"`,
+	} {
+		json, msg := json, msg
+		It(fmt.Sprintf("errors on %s", json), func() {
+			_, err := ParseConfig([]byte(json), DefaultRules)
+			Expect(err).To(MatchError(msg))
+		})
+	}
+})
+
+func HaveProblem(rule string, severity Severity, message string) gomegat.GomegaMatcher {
+	return ContainElement(&Result{Rule: rule, Severity: severity, Message: message})
+}
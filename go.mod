@@ -26,7 +26,8 @@ require (
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/spf13/viper v1.7.0
-	golang.org/x/net v0.0.0-20200520182314-0ba52f642ac2 // indirect
+	golang.org/x/net v0.0.0-20200520182314-0ba52f642ac2
+	golang.org/x/text v0.3.2
 	google.golang.org/appengine v1.6.6 // indirect
 	gopkg.in/ini.v1 v1.56.0 // indirect
 )